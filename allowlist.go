@@ -0,0 +1,33 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"sort"
+)
+
+// VerifyAllowlist verifies the signature of message against the allowlist
+// of public keys, which must be sorted in increasing order of bytes.Compare.
+// It recovers the public key from the signature once and finds it in the
+// allowlist with binary search. It returns true and the index of the
+// public key in the allowlist iff the signature is valid for one of the
+// keys, otherwise false and -1.
+//
+// It always returns false in the L-tree mode, in which the public key
+// can't be recovered from signature.
+func (s *Scheme) VerifyAllowlist(message, sig []byte, allowlist [][]byte) (bool, int) {
+	publicKey, err := s.RecoverPublicKey(message, sig)
+	if err != nil {
+		return false, -1
+	}
+	i := sort.Search(len(allowlist), func(i int) bool {
+		return bytes.Compare(allowlist[i], publicKey) >= 0
+	})
+	if i < len(allowlist) && bytes.Equal(allowlist[i], publicKey) {
+		return true, i
+	}
+	return false, -1
+}
@@ -0,0 +1,51 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestVerifyAllowlist(t *testing.T) {
+	// Use fake keys to fill the allowlist quickly.
+	var allowlist [][]byte
+	for i := 0; i < 1000; i++ {
+		k := sha256.Sum256([]byte(strconv.Itoa(i)))
+		allowlist = append(allowlist, k[:])
+	}
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowlist = append(allowlist, pub)
+	sort.Slice(allowlist, func(i, j int) bool {
+		return bytes.Compare(allowlist[i], allowlist[j]) < 0
+	})
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, i := otssha256.VerifyAllowlist(msg, sig, allowlist)
+	if !ok {
+		t.Fatalf("signature verification failed")
+	}
+	if !bytes.Equal(allowlist[i], pub) {
+		t.Fatalf("wrong index %d", i)
+	}
+	if ok, i := otssha256.VerifyAllowlist([]byte("wrong message"), sig, allowlist); ok || i != -1 {
+		t.Fatalf("verified signature for wrong message")
+	}
+	if ok, _ := otssha256.VerifyAllowlist(msg, sig, allowlist[:i]); ok {
+		t.Fatalf("verified signature for key not in allowlist")
+	}
+	if ok, _ := otssha256.VerifyAllowlist(msg, sig[1:], allowlist); ok {
+		t.Fatalf("verified signature of wrong size")
+	}
+}
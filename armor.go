@@ -0,0 +1,109 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+const (
+	armorPublicKeyBegin = "-----BEGIN WOTS PUBLIC KEY-----"
+	armorPublicKeyEnd   = "-----END WOTS PUBLIC KEY-----"
+	armorLineLen        = 64
+)
+
+// crc24 returns the OpenPGP CRC-24 checksum of b (RFC 4880, section 6.1).
+func crc24(b []byte) uint32 {
+	crc := uint32(0xb704ce)
+	for _, c := range b {
+		crc ^= uint32(c) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= 0x1864cfb
+			}
+		}
+	}
+	return crc & 0xffffff
+}
+
+// ArmorPublicKey returns the public key in the armored text format, similar
+// to OpenPGP ASCII armor: base64-encoded key split into lines, followed by
+// its CRC-24 checksum, between the BEGIN and END lines. The checksum
+// catches copy-paste errors, but doesn't protect against tampering.
+func ArmorPublicKey(pub PublicKey) string {
+	var b strings.Builder
+	b.WriteString(armorPublicKeyBegin + "\n")
+	text := base64.StdEncoding.EncodeToString(pub)
+	for len(text) > armorLineLen {
+		b.WriteString(text[:armorLineLen] + "\n")
+		text = text[armorLineLen:]
+	}
+	b.WriteString(text + "\n")
+	crc := crc24(pub)
+	b.WriteString("=" + base64.StdEncoding.EncodeToString([]byte{byte(crc >> 16), byte(crc >> 8), byte(crc)}) + "\n")
+	b.WriteString(armorPublicKeyEnd + "\n")
+	return b.String()
+}
+
+// UnarmorPublicKey decodes the public key encoded with ArmorPublicKey.
+// Errors are of type *ParseError with offsets in s; they wrap ErrChecksum
+// if the checksum doesn't match, ErrInvalidEncoding if the armor or base64
+// is malformed, or ErrWrongSize if the decoded size is not a public key
+// size of any scheme.
+func UnarmorPublicKey(s string) (PublicKey, error) {
+	var (
+		lines  []string
+		starts []int // offsets of lines in s
+	)
+	for offset := 0; offset < len(s); {
+		line := s[offset:]
+		next := len(s)
+		if i := strings.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i]
+			next = offset + i + 1
+		}
+		line = strings.TrimRight(line, " \t\r")
+		if line != "" {
+			lines = append(lines, line)
+			starts = append(starts, offset)
+		}
+		offset = next
+	}
+	if len(lines) == 0 || lines[0] != armorPublicKeyBegin {
+		return nil, &ParseError{Offset: 0, Reason: "missing armor header", Err: ErrInvalidEncoding}
+	}
+	n := len(lines)
+	if n < 4 || lines[n-1] != armorPublicKeyEnd {
+		return nil, &ParseError{Offset: len(s), Reason: "missing armor footer", Err: ErrInvalidEncoding}
+	}
+	if !strings.HasPrefix(lines[n-2], "=") {
+		return nil, &ParseError{Offset: starts[n-2], Reason: "missing armor checksum", Err: ErrInvalidEncoding}
+	}
+	sum, err := base64.StdEncoding.DecodeString(lines[n-2][1:])
+	if err != nil || len(sum) != 3 {
+		return nil, &ParseError{Offset: starts[n-2], Reason: "invalid armor checksum encoding", Err: ErrInvalidEncoding}
+	}
+	var pub []byte
+	for i := 1; i < n-2; i++ {
+		b, err := base64.StdEncoding.DecodeString(lines[i])
+		if err != nil {
+			offset := starts[i]
+			if e, ok := err.(base64.CorruptInputError); ok {
+				offset += int(e)
+			}
+			return nil, &ParseError{Offset: offset, Reason: "invalid base64 public key", Err: ErrInvalidEncoding}
+		}
+		pub = append(pub, b...)
+	}
+	if !isPublicKeySize(len(pub)) {
+		return nil, &ParseError{Offset: starts[1], Reason: "wrong public key size", Err: ErrWrongSize}
+	}
+	if crc24(pub) != uint32(sum[0])<<16|uint32(sum[1])<<8|uint32(sum[2]) {
+		return nil, &ParseError{Offset: starts[n-2], Reason: "public key checksum mismatch", Err: ErrChecksum}
+	}
+	return pub, nil
+}
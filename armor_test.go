@@ -0,0 +1,89 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestArmorPublicKey(t *testing.T) {
+	for _, s := range []*Scheme{otssha256, otssha256LTree} {
+		_, pub, err := s.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		armored := ArmorPublicKey(pub)
+		if !strings.HasPrefix(armored, armorPublicKeyBegin+"\n") ||
+			!strings.HasSuffix(armored, armorPublicKeyEnd+"\n") {
+			t.Fatalf("bad armor:\n%s", armored)
+		}
+		for _, text := range []string{armored, strings.Replace(armored, "\n", "\r\n", -1)} {
+			pub2, err := UnarmorPublicKey(text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(pub, pub2) {
+				t.Fatalf("expected %x, got %x", pub, pub2)
+			}
+		}
+	}
+}
+
+func TestCRC24(t *testing.T) {
+	// Checksum of the empty input is the initial value.
+	if crc := crc24(nil); crc != 0xb704ce {
+		t.Fatalf("expected b704ce, got %06x", crc)
+	}
+	if crc := crc24([]byte("123456789")); crc != 0x21cf02 {
+		t.Fatalf("expected 21cf02, got %06x", crc)
+	}
+}
+
+func TestUnarmorPublicKeyErrors(t *testing.T) {
+	_, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	armored := ArmorPublicKey(pub)
+	lines := strings.Split(armored, "\n")
+
+	// Flip a character of the key, keeping base64 valid.
+	key := []byte(lines[1])
+	if key[0] == 'A' {
+		key[0] = 'B'
+	} else {
+		key[0] = 'A'
+	}
+	corrupted := strings.Replace(armored, lines[1], string(key), 1)
+
+	tests := []struct {
+		name   string
+		text   string
+		target error
+	}{
+		{"corrupted key", corrupted, ErrChecksum},
+		{"corrupted checksum", strings.Replace(armored, lines[2], "=AAAA", 1), ErrChecksum},
+		{"bad base64", strings.Replace(armored, lines[1], "!"+lines[1][1:], 1), ErrInvalidEncoding},
+		{"bad checksum base64", strings.Replace(armored, lines[2], "=!!!!", 1), ErrInvalidEncoding},
+		{"no checksum", strings.Replace(armored, lines[2]+"\n", "", 1), ErrInvalidEncoding},
+		{"no header", strings.Replace(armored, lines[0], "", 1), ErrInvalidEncoding},
+		{"no footer", strings.Replace(armored, lines[3], "", 1), ErrInvalidEncoding},
+		{"wrong size", strings.Replace(armored, lines[1], lines[1][:8], 1), ErrWrongSize},
+	}
+	for _, test := range tests {
+		_, err := UnarmorPublicKey(test.text)
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("%s: expected ParseError, got %v", test.name, err)
+			continue
+		}
+		if !errors.Is(err, test.target) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.target, err)
+		}
+	}
+}
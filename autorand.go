@@ -0,0 +1,33 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+// VerifyAutoRand is like Verify, but accepts signatures made by schemes
+// that differ from this one only in the size of randomization parameter,
+// trying each of the candidate sizes, for which the signature size
+// matches, in order. Candidate size 0 means randomized hashing is
+// disabled. It returns true iff the signature is valid for one of the
+// candidates.
+func (s *Scheme) VerifyAutoRand(publicKey PublicKey, message, sig []byte, candidateRandLens []int) bool {
+	for _, n := range candidateRandLens {
+		if n != 0 && !isHashSize(n) {
+			continue
+		}
+		if len(sig) != n+s.numChains*s.blockSize {
+			continue
+		}
+		c := *s
+		c.randLen = n
+		c.verifyObserver = nil
+		if c.bindParams {
+			// The tag encodes the randomization parameter size.
+			c.paramsTag = c.encodeParamsTag()
+		}
+		if c.Verify(publicKey, message, sig) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestVerifyAutoRand(t *testing.T) {
+	peer := NewScheme(sha256.New, rand.Reader, WithRandomizerLen(16))
+	if peer.SignatureSize() != otssha256.SignatureSize()-16 {
+		t.Fatalf("wrong signature size %d", peer.SignatureSize())
+	}
+	priv, pub, err := peer.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := peer.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !peer.Verify(pub, msg, sig) {
+		t.Fatalf("signature verification failed")
+	}
+	if otssha256.Verify(pub, msg, sig) {
+		t.Fatalf("verified signature with different randomizer size")
+	}
+	if !otssha256.VerifyAutoRand(pub, msg, sig, []int{0, 32, 16}) {
+		t.Fatalf("auto-detection failed")
+	}
+	if otssha256.VerifyAutoRand(pub, msg, sig, []int{0, 32, 5}) {
+		t.Fatalf("verified signature without matching candidate")
+	}
+	if otssha256.VerifyAutoRand(pub, []byte("wrong message"), sig, []int{16}) {
+		t.Fatalf("verified signature for wrong message")
+	}
+	if !isSignatureSize(len(sig)) {
+		t.Fatalf("signature size %d not recognized", len(sig))
+	}
+	if id := peer.ID(); id != "wots-sha256-r16" {
+		t.Fatalf("expected wots-sha256-r16, got %q", id)
+	}
+	s, err := SchemeByID(peer.ID(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Verify(pub, msg, sig) {
+		t.Fatalf("scheme from ID failed to verify signature")
+	}
+	config, err := peer.MarshalConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err = UnmarshalConfig(config, rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Verify(pub, msg, sig) {
+		t.Fatalf("scheme from config failed to verify signature")
+	}
+}
+
+func TestVerifyAutoRandParamBinding(t *testing.T) {
+	s := NewScheme(sha256.New, rand.Reader, WithParamBinding())
+	peer := NewScheme(sha256.New, rand.Reader, WithParamBinding(), WithRandomizerLen(16))
+	priv, pub, err := peer.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := peer.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.VerifyAutoRand(pub, msg, sig, []int{32, 16}) {
+		t.Fatalf("auto-detection failed with parameter binding")
+	}
+	if s.VerifyAutoRand(pub, []byte("wrong message"), sig, []int{16}) {
+		t.Fatalf("verified signature for wrong message")
+	}
+}
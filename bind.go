@@ -0,0 +1,54 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+// WithParamBinding returns an option, which binds public keys to the scheme
+// parameters: a canonical encoding of the hash functions, sizes, w, and
+// other options is hashed into the public key before chain endpoints. The
+// same private key then produces different public keys under different
+// parameters, so a public key can't be used with a scheme other than the
+// one it was generated for.
+//
+// It changes public keys, which are incompatible with schemes without it.
+// Hash functions are identified by their output on a fixed input, so
+// binding works for hash functions not registered with RegisterHash.
+func WithParamBinding() Option {
+	return func(s *Scheme) { s.bindParams = true }
+}
+
+// encodeParamsTag returns the canonical encoding of the scheme parameters
+// hashed into public keys in the parameter binding mode.
+func (s *Scheme) encodeParamsTag() []byte {
+	tag := []byte("wots parameters")
+	for _, h := range []func() hash.Hash{s.hashFunc, s.chainFunc} {
+		probe := hashProbe(h)
+		tag = append(tag, byte(len(probe)))
+		tag = append(tag, probe...)
+	}
+	var b [2]byte
+	for _, n := range []int{s.digestSize, s.blockSize, s.randLen, s.w, s.numChains} {
+		binary.BigEndian.PutUint16(b[:], uint16(n))
+		tag = append(tag, b[:]...)
+	}
+	var flags byte
+	if s.ltree {
+		flags |= 1
+	}
+	if s.randHash == RandHashPrefix {
+		flags |= 2
+	}
+	if s.lenPrefix {
+		flags |= 4
+	}
+	if s.csFirst {
+		flags |= 8
+	}
+	return append(tag, flags)
+}
@@ -0,0 +1,102 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestWithParamBinding(t *testing.T) {
+	bound := NewScheme(sha256.New, rand.Reader, WithParamBinding())
+	priv, pub, err := bound.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := bound.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bound.Verify(pub, msg, sig) {
+		t.Fatalf("failed to verify signature")
+	}
+	recovered, err := bound.RecoverPublicKey(msg, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(recovered, pub) {
+		t.Fatalf("recovered public key differs")
+	}
+	if !bound.VerifyConstantTime(pub, msg, sig) {
+		t.Fatalf("failed to verify signature in constant time")
+	}
+	unbound, err := otssha256.PublicKeyFromPrivate(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(unbound, pub) {
+		t.Fatalf("bound public key equals unbound one")
+	}
+	if bound.Verify(unbound, msg, sig) {
+		t.Fatalf("bound scheme verified signature with unbound public key")
+	}
+
+	other := NewScheme(sha256.New, rand.Reader, WithParamBinding(), WithRandHash(RandHashPrefix))
+	otherPub, err := other.PublicKeyFromPrivate(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(otherPub, pub) {
+		t.Fatalf("public keys under different parameters are equal")
+	}
+
+	ltree := NewScheme(sha256.New, rand.Reader, WithParamBinding(), WithLTree())
+	ltreePub, err := ltree.PublicKeyFromPrivate(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ltreeUnbound, err := otssha256LTree.PublicKeyFromPrivate(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(ltreePub, ltreeUnbound) {
+		t.Fatalf("bound L-tree public key equals unbound one")
+	}
+	sig, err = ltree.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ltree.Verify(ltreePub, msg, sig) {
+		t.Fatalf("failed to verify signature in L-tree mode")
+	}
+}
+
+func TestWithParamBindingID(t *testing.T) {
+	s := NewScheme(sha256.New, rand.Reader, WithParamBinding())
+	if id := s.ID(); id != "wots-sha256-bind" {
+		t.Fatalf("expected ID %q, got %q", "wots-sha256-bind", id)
+	}
+	s2, err := SchemeByID(s.ID(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Compatible(s2) {
+		t.Fatalf("scheme from ID is not compatible")
+	}
+	config, err := s.MarshalConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s3, err := UnmarshalConfig(config, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Compatible(s3) {
+		t.Fatalf("unmarshaled scheme is not compatible")
+	}
+}
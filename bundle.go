@@ -0,0 +1,40 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+// Bundle is a self-contained signed message with plain field types,
+// suitable for transferring with RPC frameworks.
+type Bundle struct {
+	SchemeID  string // scheme ID, as returned by Scheme.ID
+	PublicKey []byte
+	Message   []byte
+	Signature []byte
+}
+
+// Bundle returns a bundle of the signature of message, the public key,
+// and the scheme ID.
+func (s *Scheme) Bundle(publicKey PublicKey, message, sig []byte) Bundle {
+	return Bundle{
+		SchemeID:  s.ID(),
+		PublicKey: publicKey,
+		Message:   message,
+		Signature: sig,
+	}
+}
+
+// Verify looks up the scheme by the bundle's scheme ID with SchemeByID,
+// verifies the signature of the message using the public key, and returns
+// true iff the signature is valid. It returns an error if the scheme
+// can't be determined.
+//
+// The caller must check that the public key is trusted and the scheme is
+// acceptable.
+func (b Bundle) Verify() (bool, error) {
+	s, err := SchemeByID(b.SchemeID, nil)
+	if err != nil {
+		return false, err
+	}
+	return s.Verify(b.PublicKey, b.Message, b.Signature), nil
+}
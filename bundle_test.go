@@ -0,0 +1,42 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "testing"
+
+func TestBundle(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := otssha256.Bundle(pub, msg, sig)
+	if b.SchemeID != "wots-sha256" {
+		t.Fatalf("expected scheme ID wots-sha256, got %q", b.SchemeID)
+	}
+	ok, err := b.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("signature verification failed")
+	}
+	b.Message = []byte("wrong message")
+	ok, err = b.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf("verified signature for wrong message")
+	}
+	b.SchemeID = "wots-unknown"
+	if _, err := b.Verify(); err == nil {
+		t.Fatalf("no error for unknown scheme")
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+// ChainHasher hashes chains with addresses, for example, with tweakable
+// hash functions as in WOTS+ and SPHINCS+.
+//
+// Chain returns a new block, which is the result of applying steps hashing
+// steps to the input block in, which is the value at position start of the
+// chain with the given address, or a copy of in if steps is 0. Step number
+// start+i must be computed from the value at position start+i. The address
+// is the 4-byte big-endian chain index; implementations must not retain it.
+type ChainHasher interface {
+	Chain(in []byte, start, steps int, addr []byte) []byte
+}
+
+// hashChainHasher is the default chain hasher, which ignores addresses.
+type hashChainHasher struct {
+	h func() hash.Hash
+}
+
+// NewHashChainHasher returns a chain hasher, which hashes blocks with the
+// given hash function without addresses, as schemes without a chain hasher
+// do. It's useful as a base for tweaked implementations.
+func NewHashChainHasher(h func() hash.Hash) ChainHasher {
+	return hashChainHasher{h}
+}
+
+func (c hashChainHasher) Chain(in []byte, start, steps int, addr []byte) []byte {
+	return hashBlock(c.h(), in, steps)
+}
+
+// WithChainHasher returns an option, which sets the chain hasher used for
+// key generation, signing, and verification instead of the chain hash
+// function. It overrides WithChainExecutor. The chain hasher must return
+// blocks of the chain hash output size.
+//
+// Schemes with a chain hasher have no ID and can't be marshaled with
+// MarshalConfig.
+func WithChainHasher(c ChainHasher) Option {
+	return func(s *Scheme) { s.chainHasher = c }
+}
+
+// appendAddressedChain is like appendChain, but uses the chain hasher.
+func (s *Scheme) appendAddressedChain(dst, in []byte, index, start, times int) []byte {
+	var addr [4]byte
+	binary.BigEndian.PutUint32(addr[:], uint32(index))
+	out := s.chainHasher.Chain(in, start, times, addr[:])
+	if len(out) != s.blockSize {
+		panic("wots: chain hasher returned wrong block size")
+	}
+	return append(dst, out...)
+}
@@ -0,0 +1,150 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+)
+
+// tweakedChainHasher hashes each step as H(addr ‖ position ‖ value).
+type tweakedChainHasher struct{}
+
+func (tweakedChainHasher) Chain(in []byte, start, steps int, addr []byte) []byte {
+	out := append([]byte(nil), in...)
+	h := sha256.New()
+	var pos [4]byte
+	for i := start; i < start+steps; i++ {
+		binary.BigEndian.PutUint32(pos[:], uint32(i))
+		h.Reset()
+		h.Write(addr)
+		h.Write(pos[:])
+		h.Write(out)
+		out = h.Sum(out[:0])
+	}
+	return out
+}
+
+func TestNewHashChainHasher(t *testing.T) {
+	s := NewScheme(sha256.New, zeroReader, WithChainHasher(NewHashChainHasher(sha256.New)))
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultPub, err := otssha256Insecure.PublicKeyFromPrivate(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pub, defaultPub) {
+		t.Fatalf("public key differs from default")
+	}
+	msg := []byte(testMessage)
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultSig, err := otssha256Insecure.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, defaultSig) {
+		t.Fatalf("signature differs from default")
+	}
+	if s.ID() != "" {
+		t.Fatalf("expected empty ID for scheme with chain hasher, got %q", s.ID())
+	}
+	if _, err := s.MarshalConfig(); err == nil {
+		t.Fatalf("no error marshaling scheme with chain hasher")
+	}
+}
+
+func TestWithChainHasher(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithLTree()}, {WithW(4)}} {
+		s := NewScheme(sha256.New, zeroReader, append(opts, WithChainHasher(tweakedChainHasher{}))...)
+		defaultScheme := NewScheme(sha256.New, zeroReader, opts...)
+		priv, pub, err := s.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defaultPub, err := defaultScheme.PublicKeyFromPrivate(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(pub, defaultPub) {
+			t.Fatalf("tweaked public key equals default one")
+		}
+		p := s.NewKeygenProgress(priv)
+		for !p.Step(10) {
+		}
+		progressPub, err := p.PublicKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(progressPub, pub) {
+			t.Fatalf("KeygenProgress public key differs")
+		}
+		msg := []byte(testMessage)
+		sig, err := s.Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.Verify(pub, msg, sig) {
+			t.Fatalf("failed to verify signature")
+		}
+		if !s.VerifyConstantTime(pub, msg, sig) {
+			t.Fatalf("failed to verify signature in constant time")
+		}
+		if !NewVerifierState(s).Verify(pub, msg, sig) {
+			t.Fatalf("failed to verify signature with VerifierState")
+		}
+		if defaultScheme.Verify(pub, msg, sig) {
+			t.Fatalf("default scheme verified tweaked signature")
+		}
+		if s.Verify(pub, msg[1:], sig) {
+			t.Fatalf("verified wrong message")
+		}
+	}
+}
+
+// boundedChainHasher is a chain hasher, which panics if asked to hash
+// steps outside of a chain of the given length.
+type boundedChainHasher struct {
+	ChainHasher
+	chainLen int
+}
+
+func (c boundedChainHasher) Chain(in []byte, start, steps int, addr []byte) []byte {
+	if start < 0 || steps < 0 || start+steps > c.chainLen {
+		panic("chain position out of range")
+	}
+	return c.ChainHasher.Chain(in, start, steps, addr)
+}
+
+func TestChainHasherPositions(t *testing.T) {
+	for _, w := range []int{4, 8} {
+		c := boundedChainHasher{tweakedChainHasher{}, 1 << uint(w)}
+		s := NewScheme(sha256.New, zeroReader, WithW(w), WithChainHasher(c))
+		priv, pub, err := s.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := []byte(testMessage)
+		sig, err := s.Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.Verify(pub, msg, sig) {
+			t.Fatalf("w=%d: failed to verify signature", w)
+		}
+		if !s.VerifyConstantTime(pub, msg, sig) {
+			t.Fatalf("w=%d: failed to verify signature in constant time", w)
+		}
+		if s.VerifyConstantTime(pub, msg[1:], sig) {
+			t.Fatalf("w=%d: verified signature of wrong message in constant time", w)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "errors"
+
+// Combine returns the combined blob of the public key followed by the
+// signature, which can be verified with VerifyCombined.
+func Combine(publicKey PublicKey, sig []byte) []byte {
+	blob := make([]byte, 0, len(publicKey)+len(sig))
+	blob = append(blob, publicKey...)
+	return append(blob, sig...)
+}
+
+// VerifyCombined splits blob, consisting of the public key followed by the
+// signature of message, verifies the signature using the public key, and
+// returns the result along with the public key. It returns an error if
+// the blob size is not PublicKeySize plus SignatureSize.
+//
+// The caller must check that the returned public key is trusted.
+func (s *Scheme) VerifyCombined(message, blob []byte) (bool, PublicKey, error) {
+	if len(blob) != s.PublicKeySize()+s.SignatureSize() {
+		return false, nil, errors.New("wots: combined public key and signature size doesn't match the scheme")
+	}
+	publicKey := PublicKey(blob[:s.PublicKeySize()])
+	return s.Verify(publicKey, message, blob[s.PublicKeySize():]), publicKey, nil
+}
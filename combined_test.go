@@ -0,0 +1,42 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestVerifyCombined(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := Combine(pub, sig)
+	ok, pub2, err := otssha256.VerifyCombined(msg, blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("failed to verify combined blob")
+	}
+	if !bytes.Equal(pub, pub2) {
+		t.Fatalf("expected public key %x, got %x", pub, pub2)
+	}
+	if ok, _, err := otssha256.VerifyCombined(msg[1:], blob); ok || err != nil {
+		t.Fatalf("wrong message: expected false, nil; got %v, %v", ok, err)
+	}
+	if _, _, err := otssha256.VerifyCombined(msg, blob[1:]); err == nil {
+		t.Fatalf("no error for short blob")
+	}
+	if _, _, err := otssha256.VerifyCombined(msg, append(blob, 0)); err == nil {
+		t.Fatalf("no error for long blob")
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"hash"
+	"io"
+)
+
+// combinedHash is a hash.Hash, which hashes data with two hash functions
+// and returns the concatenation of their outputs.
+type combinedHash struct {
+	a, b hash.Hash
+}
+
+func (h *combinedHash) Write(p []byte) (int, error) {
+	h.a.Write(p)
+	h.b.Write(p)
+	return len(p), nil
+}
+
+func (h *combinedHash) Sum(b []byte) []byte {
+	return appendHashSum(appendHashSum(b, h.a), h.b)
+}
+
+func (h *combinedHash) Reset() {
+	h.a.Reset()
+	h.b.Reset()
+}
+
+func (h *combinedHash) Size() int      { return h.a.Size() + h.b.Size() }
+func (h *combinedHash) BlockSize() int { return h.a.BlockSize() }
+
+// NewSchemeCombined is like NewSchemeChecked, but uses for both message and
+// chain hashing the combination of two hash functions, H(x) = h1(x) ‖ h2(x),
+// for example, SHA-256 and SHA3-256, so that a break of one of them doesn't
+// immediately break the scheme. The output size of the combination is the
+// sum of output sizes of h1 and h2, and must not exceed MaxHashSize.
+//
+// The combination is about as slow as both hash functions together, and
+// makes keys and signatures twice as large as with one of them.
+func NewSchemeCombined(h1, h2 func() hash.Hash, rand io.Reader, opts ...Option) (*Scheme, error) {
+	h := func() hash.Hash { return &combinedHash{h1(), h2()} }
+	return NewSchemeChecked(h, h, rand, opts...)
+}
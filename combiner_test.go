@@ -0,0 +1,51 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"hash"
+	"testing"
+)
+
+func TestNewSchemeCombined(t *testing.T) {
+	sha3New256 := func() hash.Hash { return sha3.New256() }
+	s, err := NewSchemeCombined(sha256.New, sha3New256, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.digestSize != 64 || s.blockSize != 64 {
+		t.Fatalf("expected digest and block sizes 64, got %d and %d", s.digestSize, s.blockSize)
+	}
+	h := s.hashFunc()
+	h.Write([]byte(testMessage))
+	d1 := sha256.Sum256([]byte(testMessage))
+	d2 := sha3.Sum256([]byte(testMessage))
+	if expected := append(d1[:], d2[:]...); !bytes.Equal(h.Sum(nil), expected) {
+		t.Fatalf("combined hash: expected %x, got %x", expected, h.Sum(nil))
+	}
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Verify(pub, msg, sig) {
+		t.Fatalf("failed to verify signature")
+	}
+	if s.Verify(pub, msg[1:], sig) {
+		t.Fatalf("verified wrong message")
+	}
+	if _, err := NewSchemeCombined(sha512.New, newXOFHash(65), rand.Reader); err == nil {
+		t.Fatalf("no error for combined hash longer than MaxHashSize")
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "crypto/subtle"
+
+// Commitment returns the commitment to the public key, which is the hash
+// of it with the scheme's message hash function. The commitment can be
+// distributed instead of the public key, and signatures verified against
+// it with VerifyAgainstCommitment.
+func (s *Scheme) Commitment(publicKey PublicKey) []byte {
+	h := s.hashFunc()
+	h.Write(publicKey)
+	return h.Sum(nil)
+}
+
+// VerifyAgainstCommitment verifies the signature of message using the
+// commitment to the public key returned by Commitment, and returns true
+// iff the signature is valid. It always returns false in the L-tree mode,
+// in which the public key can't be recovered from signature.
+func (s *Scheme) VerifyAgainstCommitment(commitment, message, sig []byte) bool {
+	publicKey, err := s.RecoverPublicKey(message, sig)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(s.Commitment(publicKey), commitment) == 1
+}
@@ -0,0 +1,36 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "testing"
+
+func TestVerifyAgainstCommitment(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment := otssha256.Commitment(pub)
+	if !otssha256.VerifyAgainstCommitment(commitment, msg, sig) {
+		t.Fatalf("signature verification against commitment failed")
+	}
+	if otssha256.VerifyAgainstCommitment(commitment, []byte("wrong message"), sig) {
+		t.Fatalf("verified signature for wrong message")
+	}
+	_, otherPub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otssha256.VerifyAgainstCommitment(otssha256.Commitment(otherPub), msg, sig) {
+		t.Fatalf("verified signature against wrong commitment")
+	}
+	if otssha256.VerifyAgainstCommitment(commitment, msg, sig[1:]) {
+		t.Fatalf("verified signature of wrong size")
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"context"
+)
+
+// contextCheck returns a chain check, which returns the context error once
+// ctx is done.
+func contextCheck(ctx context.Context) chainCheck {
+	done := ctx.Done()
+	return func(int) error {
+		select {
+		case <-done:
+			return ctx.Err()
+		default:
+			return nil
+		}
+	}
+}
+
+// GenerateKeyPairContext is like GenerateKeyPair, but checks ctx between
+// hash chains and returns the context error once it's done.
+func (s *Scheme) GenerateKeyPairContext(ctx context.Context) (PrivateKey, PublicKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	return s.generateKeyPair(contextCheck(ctx))
+}
+
+// SignContext is like Sign, but checks ctx between hash chains and
+// returns the context error once it's done. A partially computed
+// signature is discarded. The private key must be treated as spent once
+// SignContext is called, whether or not it returns a signature, since the
+// caller can't tell how far signing got.
+//
+// IMPORTANT: Do not use the same private key to sign more than one message!
+// It's a one-time signature.
+func (s *Scheme) SignContext(ctx context.Context, privateKey PrivateKey, message []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.newSigner().signCheck(make([]byte, 0, s.SignatureSize()), privateKey, message, contextCheck(ctx))
+}
+
+// VerifyContext is the same as VerifyWithDeadline.
+func (s *Scheme) VerifyContext(ctx context.Context, publicKey PublicKey, message, sig []byte) (bool, error) {
+	return s.VerifyWithDeadline(ctx, publicKey, message, sig)
+}
+
+// VerifyWithDeadline is like Verify, but checks ctx between hash chains
+// and aborts verification once it's done, returning false and the context
+// error. It allows verifiers facing adversarial input, for example with
+// slow chain executors, to bound the time spent on a single signature.
+//
+// Wrong public key or signature sizes are rejected before any hashing.
+func (s *Scheme) VerifyWithDeadline(ctx context.Context, publicKey PublicKey, message, sig []byte) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if !s.checkVerifySizes(publicKey, sig) {
+		return false, nil
+	}
+	salt, key := s.splitPublicKey(publicKey)
+	recovered, err := s.recoverMessageKeyCheck(nil, salt, message, sig, contextCheck(ctx))
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(recovered, key) {
+		s.observeVerify(VerifyMismatch)
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestVerifyWithDeadline(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := otssha256.VerifyWithDeadline(context.Background(), pub, msg, sig)
+	if !ok || err != nil {
+		t.Fatalf("correct signature: expected true, nil; got %v, %v", ok, err)
+	}
+	sig[len(sig)-1] ^= 1
+	ok, err = otssha256.VerifyWithDeadline(context.Background(), pub, msg, sig)
+	if ok || err != nil {
+		t.Fatalf("corrupted signature: expected false, nil; got %v, %v", ok, err)
+	}
+	ok, err = otssha256.VerifyWithDeadline(context.Background(), pub, msg, sig[1:])
+	if ok || err != nil {
+		t.Fatalf("wrong signature size: expected false, nil; got %v, %v", ok, err)
+	}
+}
+
+func TestVerifyWithDeadlineCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls int
+	executor := func(in []byte, times int) []byte {
+		calls++
+		cancel()
+		return hashBlock(sha256.New(), in, times)
+	}
+	s := NewScheme(sha256.New, rand.Reader, WithChainExecutor(executor))
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := s.VerifyWithDeadline(ctx, pub, msg, sig)
+	if ok || err != context.Canceled {
+		t.Fatalf("expected false, %v; got %v, %v", context.Canceled, ok, err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected verification to stop after 1 chain, computed %d", calls)
+	}
+	calls = 0
+	ok, err = s.VerifyWithDeadline(ctx, pub, msg, sig)
+	if ok || err != context.Canceled || calls != 0 {
+		t.Fatalf("already canceled: expected false, %v, 0 chains; got %v, %v, %d",
+			context.Canceled, ok, err, calls)
+	}
+}
+
+func TestGenerateKeyPairContext(t *testing.T) {
+	for _, s := range []*Scheme{otssha256Insecure, NewScheme(sha256.New, zeroReader, WithLTree())} {
+		priv, pub, err := s.GenerateKeyPairContext(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := s.PublicKeyFromPrivate(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(pub, expected) {
+			t.Fatalf("public key differs from PublicKeyFromPrivate")
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls int
+	s := NewScheme(sha256.New, rand.Reader, WithChainExecutor(func(in []byte, times int) []byte {
+		calls++
+		return hashBlock(sha256.New(), in, times)
+	}))
+	if _, _, err := s.GenerateKeyPairContext(ctx); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	if calls != 0 {
+		t.Fatalf("canceled context: expected no chains computed, got %d", calls)
+	}
+}
+
+func TestSignContext(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.SignContext(context.Background(), priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := otssha256.VerifyContext(context.Background(), pub, msg, sig)
+	if !ok || err != nil {
+		t.Fatalf("expected true, nil; got %v, %v", ok, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := otssha256.SignContext(ctx, priv, msg); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	if _, err := otssha256.VerifyContext(ctx, pub, msg, sig); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+}
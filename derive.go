@@ -0,0 +1,191 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"crypto/hmac"
+	"encoding/binary"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// expandSeed fills out with bytes derived from seed, info and index
+// using HMAC in counter mode.
+func (s *Scheme) expandSeed(out, seed []byte, info string, index uint64) {
+	var b [12]byte
+	binary.BigEndian.PutUint64(b[:8], index)
+	mac := hmac.New(s.hashFunc, seed)
+	var block []byte
+	for counter := uint32(0); len(out) > 0; counter++ {
+		binary.BigEndian.PutUint32(b[8:], counter)
+		mac.Reset()
+		mac.Write([]byte(info))
+		mac.Write(b[:])
+		block = mac.Sum(block[:0])
+		out = out[copy(out, block):]
+	}
+	for i := range block {
+		block[i] = 0
+	}
+}
+
+// DeriveKeyPair deterministically derives a private and public key pair
+// with the given index from seed, which must be secret, random, and at
+// least SeedSize bytes long. Different indexes produce independent key pairs.
+//
+// Each derived private key must be used to sign only one message.
+func (s *Scheme) DeriveKeyPair(seed []byte, index uint64) (PrivateKey, PublicKey, error) {
+	if !s.validHashSizes() {
+		return nil, nil, errors.New("wots: wrong hash output size")
+	}
+	if len(seed) < s.SeedSize() {
+		return nil, nil, errors.New("wots: seed is too short")
+	}
+	privateKey := make([]byte, s.PrivateKeySize())
+	s.expandSeed(privateKey, seed, "wots private key", index)
+	publicKey, err := s.PublicKeyFromPrivate(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return privateKey, publicKey, nil
+}
+
+// VerifyFromSeed is like Verify, but uses the public key of the key pair
+// derived from seed and index with DeriveKeyPair, for example, in tests or
+// when the verifier shares the seed with the signer. A seed alone doesn't
+// determine a key pair, since DeriveKeyPair derives one per index, so the
+// index of the signer's key pair must be given; signers deriving a single
+// key pair from the seed can use index 0. The private key is wiped right
+// after deriving the public key. It returns an error if the key pair
+// can't be derived.
+func (s *Scheme) VerifyFromSeed(seed []byte, index uint64, message, sig []byte) (bool, error) {
+	privateKey, publicKey, err := s.DeriveKeyPair(seed, index)
+	if err != nil {
+		return false, err
+	}
+	for i := range privateKey {
+		privateKey[i] = 0
+	}
+	return s.Verify(publicKey, message, sig), nil
+}
+
+// PublicKeyStream returns a function, which on each call returns the
+// public key of the next key pair derived from seed with DeriveKeyPair,
+// starting from index 0. Private keys are wiped right after deriving the
+// public keys from them.
+//
+// The returned function is not safe for concurrent use.
+func (s *Scheme) PublicKeyStream(seed []byte) func() (PublicKey, error) {
+	seed = append([]byte(nil), seed...)
+	var index uint64
+	return func() (PublicKey, error) {
+		privateKey, publicKey, err := s.DeriveKeyPair(seed, index)
+		if err != nil {
+			return nil, err
+		}
+		for i := range privateKey {
+			privateKey[i] = 0
+		}
+		index++
+		return publicKey, nil
+	}
+}
+
+// LeafPublicKeys returns public keys of n key pairs derived from seed with
+// DeriveKeyPair, with indexes from 0 to n-1, for example, to build a Merkle
+// tree of one-time keys. Keys are derived in parallel by the given number
+// of goroutines, or, if parallelism is 0, on all available CPUs. Private
+// keys are wiped right after deriving public keys.
+func (s *Scheme) LeafPublicKeys(seed []byte, n, parallelism int) ([]PublicKey, error) {
+	if !s.validHashSizes() {
+		return nil, errors.New("wots: wrong hash output size")
+	}
+	if len(seed) < s.SeedSize() {
+		return nil, errors.New("wots: seed is too short")
+	}
+	if n < 0 {
+		return nil, errors.New("wots: negative number of keys")
+	}
+	if parallelism < 0 {
+		return nil, errors.New("wots: negative parallelism")
+	}
+	keys := make([]PublicKey, n)
+	var (
+		next int64
+		wg   sync.WaitGroup
+	)
+	for workers := numWorkers(parallelism, n); workers > 0; workers-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			privateKey := make([]byte, s.PrivateKeySize())
+			blockHash := s.chainFunc()
+			keyHash := s.newKeyHasher(nil)
+			endpoints := s.newEndpoints()
+			for {
+				i := atomic.AddInt64(&next, 1) - 1
+				if i >= int64(n) {
+					break
+				}
+				s.expandSeed(privateKey, seed, "wots private key", uint64(i))
+				keys[i] = s.appendPublicKey(nil, privateKey, blockHash, keyHash, endpoints)
+			}
+			for i := range privateKey {
+				privateKey[i] = 0
+			}
+		}()
+	}
+	wg.Wait()
+	return keys, nil
+}
+
+// numWorkers returns the number of goroutines for processing n items
+// with the given parallelism, which is the number of available CPUs if 0.
+func numWorkers(parallelism, n int) int {
+	if parallelism == 0 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	if parallelism > n {
+		parallelism = n
+	}
+	return parallelism
+}
+
+// GenerateKeyPairMixed is like GenerateKeyPair, but mixes extra entropy
+// provided by the caller, for example, from a hardware token, into the
+// randomness read from the scheme's random reader, so that the private key
+// can't be predicted from the reader output alone.
+//
+// The private key is derived with HMAC, keyed by SeedSize bytes read from
+// the random reader, of extra, and then expanded like in DeriveKeyPair.
+func (s *Scheme) GenerateKeyPairMixed(extra []byte) (PrivateKey, PublicKey, error) {
+	if !s.validHashSizes() {
+		return nil, nil, errors.New("wots: wrong hash output size")
+	}
+	seed := make([]byte, s.SeedSize())
+	if _, err := io.ReadFull(s.rand, seed); err != nil {
+		return nil, nil, err
+	}
+	mac := hmac.New(s.hashFunc, seed)
+	mac.Write([]byte("wots mixed entropy"))
+	mac.Write(extra)
+	mixed := mac.Sum(nil)
+	privateKey := make([]byte, s.PrivateKeySize())
+	s.expandSeed(privateKey, mixed, "wots private key", 0)
+	for i := range seed {
+		seed[i] = 0
+	}
+	for i := range mixed {
+		mixed[i] = 0
+	}
+	publicKey, err := s.PublicKeyFromPrivate(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return privateKey, publicKey, nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+var testSeed = []byte("0123456789abcdef0123456789abcdef")
+
+func TestDeriveKeyPair(t *testing.T) {
+	priv, pub, err := otssha256.DeriveKeyPair(testSeed, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv2, pub2, err := otssha256.DeriveKeyPair(testSeed, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priv, priv2) || !bytes.Equal(pub, pub2) {
+		t.Fatalf("derived different key pairs from the same seed and index")
+	}
+	_, pub3, err := otssha256.DeriveKeyPair(testSeed, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(pub, pub3) {
+		t.Fatalf("derived the same key pair for different indexes")
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !otssha256.Verify(pub, msg, sig) {
+		t.Fatalf("failed to verify signature made with derived key")
+	}
+	if _, _, err := otssha256.DeriveKeyPair(testSeed[:31], 1); err == nil {
+		t.Fatalf("no error for short seed")
+	}
+}
+
+func TestVerifyFromSeed(t *testing.T) {
+	priv, pub, err := otssha256.DeriveKeyPair(testSeed, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range [][]byte{msg, msg[1:]} {
+		ok, err := otssha256.VerifyFromSeed(testSeed, 3, m, sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := otssha256.Verify(pub, m, sig); ok != expected {
+			t.Fatalf("expected %v, got %v", expected, ok)
+		}
+	}
+	if ok, err := otssha256.VerifyFromSeed(testSeed, 4, msg, sig); ok || err != nil {
+		t.Fatalf("wrong index: expected false, nil; got %v, %v", ok, err)
+	}
+	if _, err := otssha256.VerifyFromSeed(testSeed[:31], 3, msg, sig); err == nil {
+		t.Fatalf("no error for short seed")
+	}
+}
+
+func TestPublicKeyStream(t *testing.T) {
+	next := otssha256.PublicKeyStream(testSeed)
+	for i := uint64(0); i < 3; i++ {
+		pub, err := next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, expected, err := otssha256.DeriveKeyPair(testSeed, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(pub, expected) {
+			t.Fatalf("%d: expected %x, got %x", i, expected, pub)
+		}
+	}
+}
+
+func TestGenerateKeyPairMixed(t *testing.T) {
+	newScheme := func() *Scheme {
+		return NewScheme(sha256.New, bytes.NewReader(testSeed))
+	}
+	priv1, pub1, err := newScheme().GenerateKeyPairMixed([]byte("extra 1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv2, _, err := newScheme().GenerateKeyPairMixed([]byte("extra 2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(priv1, priv2) {
+		t.Fatalf("different extra entropy produced the same key")
+	}
+	again, _, err := newScheme().GenerateKeyPairMixed([]byte("extra 1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priv1, again) {
+		t.Fatalf("the same inputs produced different keys")
+	}
+	msg := []byte(testMessage)
+	s := NewScheme(sha256.New, zeroReader)
+	sig, err := s.Sign(priv1, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Verify(pub1, msg, sig) {
+		t.Fatalf("signature verification failed")
+	}
+	if _, _, err := newScheme().GenerateKeyPairMixed(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := NewScheme(sha256.New, bytes.NewReader(testSeed[1:])).GenerateKeyPairMixed(nil); err == nil {
+		t.Fatalf("no error for short random reader")
+	}
+}
+
+func TestLeafPublicKeys(t *testing.T) {
+	for _, s := range []*Scheme{otssha256, otssha256LTree} {
+		keys, err := s.LeafPublicKeys(testSeed, 5, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(keys) != 5 {
+			t.Fatalf("expected 5 keys, got %d", len(keys))
+		}
+		for i, pub := range keys {
+			_, expected, err := s.DeriveKeyPair(testSeed, uint64(i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(pub, expected) {
+				t.Fatalf("%d: expected %x, got %x", i, expected, pub)
+			}
+		}
+	}
+	if keys, err := otssha256.LeafPublicKeys(testSeed, 0, 0); err != nil || len(keys) != 0 {
+		t.Fatalf("zero keys: expected no keys and no error, got %d, %v", len(keys), err)
+	}
+	if _, err := otssha256.LeafPublicKeys(testSeed[:31], 1, 0); err == nil {
+		t.Fatalf("no error for short seed")
+	}
+}
+
+func TestLeafPublicKeysParallelism(t *testing.T) {
+	const n = 8
+	serial, err := otssha256.LeafPublicKeys(testSeed, n, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, parallelism := range []int{0, 2, 3, 16} {
+		keys, err := otssha256.LeafPublicKeys(testSeed, n, parallelism)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range keys {
+			if !bytes.Equal(keys[i], serial[i]) {
+				t.Fatalf("parallelism %d: key %d differs from serial result", parallelism, i)
+			}
+		}
+	}
+	if _, err := otssha256.LeafPublicKeys(testSeed, n, -1); err == nil {
+		t.Fatalf("no error for negative parallelism")
+	}
+	if w := numWorkers(1, n); w != 1 {
+		t.Fatalf("parallelism 1: expected 1 worker, got %d", w)
+	}
+	if w := numWorkers(16, n); w != n {
+		t.Fatalf("parallelism 16: expected %d workers, got %d", n, w)
+	}
+}
+
+func BenchmarkLeafPublicKeys(b *testing.B) {
+	const n = 64
+	for i := 0; i < b.N; i++ {
+		if _, err := otssha256.LeafPublicKeys(testSeed, n, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
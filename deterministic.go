@@ -0,0 +1,50 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+)
+
+// deterministicReader is a reader of SHA-256 counter mode keystream.
+type deterministicReader struct {
+	seed    []byte
+	counter uint64
+	buf     []byte // unread keystream bytes
+}
+
+// NewDeterministicReader returns a reader, which produces a deterministic
+// non-constant keystream derived from seed: SHA-256(seed ‖ counter) for
+// big-endian 64-bit counter values starting from 0. Readers with the same
+// seed produce the same bytes.
+//
+// It's intended for reproducible tests and benchmarks, where it's more
+// realistic than a reader of zeros.
+//
+// WARNING: Never use it for generating real keys!
+func NewDeterministicReader(seed []byte) io.Reader {
+	return &deterministicReader{seed: append([]byte(nil), seed...)}
+}
+
+func (r *deterministicReader) Read(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if len(r.buf) == 0 {
+			var c [8]byte
+			binary.BigEndian.PutUint64(c[:], r.counter)
+			r.counter++
+			h := sha256.New()
+			h.Write(r.seed)
+			h.Write(c[:])
+			r.buf = h.Sum(r.buf[:0])
+		}
+		k := copy(p, r.buf)
+		p = p[k:]
+		r.buf = r.buf[k:]
+	}
+	return n, nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDeterministicReader(t *testing.T) {
+	seed := []byte("seed")
+	a := make([]byte, 1000)
+	if _, err := io.ReadFull(NewDeterministicReader(seed), a); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 1000)
+	if _, err := io.ReadFull(iotest.OneByteReader(NewDeterministicReader(seed)), b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Fatalf("readers with the same seed produced different bytes")
+	}
+	first := sha256.Sum256(append(seed, 0, 0, 0, 0, 0, 0, 0, 0))
+	if !bytes.Equal(a[:32], first[:]) {
+		t.Fatalf("expected %x, got %x", first, a[:32])
+	}
+	if bytes.Equal(a[:32], a[32:64]) {
+		t.Fatalf("keystream repeats")
+	}
+	c := make([]byte, 1000)
+	if _, err := io.ReadFull(NewDeterministicReader([]byte("other")), c); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(a, c) {
+		t.Fatalf("readers with different seeds produced the same bytes")
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "bytes"
+
+// VerifyDistance is like Verify, but also returns the number of bytes
+// in which the public key recovered from the signature differs from the
+// given public key. It's zero for valid signatures, and for random
+// corruption it's close to the public key size. If the public key or
+// signature size is wrong, it returns false and -1.
+func (s *Scheme) VerifyDistance(publicKey PublicKey, message, sig []byte) (ok bool, byteDiffs int) {
+	if !s.checkVerifySizes(publicKey, sig) {
+		return false, -1
+	}
+	salt, key := s.splitPublicKey(publicKey)
+	recovered := s.recoverMessageKey(nil, salt, message, sig)
+	for i := range key {
+		if key[i] != recovered[i] {
+			byteDiffs++
+		}
+	}
+	if byteDiffs != 0 {
+		s.observeVerify(VerifyMismatch)
+	}
+	return byteDiffs == 0, byteDiffs
+}
+
+// RevealedBytesFor returns the number of bytes of secret chain values,
+// which a signature with the given message digits with checksum, as
+// returned by MessageDigits, reveals. A chain with digit v reveals the
+// value hashed v times from the private key block, from which everyone
+// can compute the chainLen - v values following it up to, but excluding,
+// the chain endpoint in the public key. Digit 0 reveals the private key
+// block itself.
+//
+// The result shows that signatures of different messages reveal different
+// parts of chains, which is why signing two messages with the same key
+// allows forgeries. It returns -1 if the number of digits is wrong or any
+// digit is out of range.
+func (s *Scheme) RevealedBytesFor(digits []int) int {
+	if len(digits) != s.numChains {
+		return -1
+	}
+	n := 0
+	for _, v := range digits {
+		if v < 0 || v >= s.chainLen {
+			return -1
+		}
+		n += (s.chainLen - v) * s.blockSize
+	}
+	return n
+}
+
+// RecoverMessageDigest returns the digest of message, which sig claims to
+// sign, calculated without randomization, that is, with the plain message
+// hash function. It's the same for all signatures of the message, so it
+// can be used for deduplicating stored signatures by message.
+//
+// Signatures themselves can't be normalized: each signature is made with
+// a new random randomization parameter, which changes the randomized
+// message digest and thus every revealed chain value. The result can't be
+// used to verify sig; it returns nil if the signature size is wrong.
+func (s *Scheme) RecoverMessageDigest(sig, message []byte) []byte {
+	if len(sig) != s.SignatureSize() {
+		return nil
+	}
+	return s.messageDigest(nil, message)
+}
+
+// ForgeableAfterReuse reports whether two signatures made with the same
+// private key, with the given message digits with checksum, as returned by
+// MessageDigits, reveal enough chain values to sign a different digit
+// vector. For each chain, the smaller of the two digits reveals the lower
+// chain value, from which an attacker can compute values for all larger
+// digits, so any valid digit vector not less than the per-chain minimums
+// in every digit, other than the two signed ones, can be forged by finding
+// a message with such digits.
+//
+// It's intended for assessing the damage when key reuse is detected, and
+// returns false if the number of digits is wrong or any digit is out of
+// range.
+func (s *Scheme) ForgeableAfterReuse(digitsA, digitsB []int) bool {
+	if len(digitsA) != s.numChains || len(digitsB) != s.numChains {
+		return false
+	}
+	min := make([]int, s.numChains)
+	for i := range min {
+		a, b := digitsA[i], digitsB[i]
+		if a < 0 || a >= s.chainLen || b < 0 || b >= s.chainLen {
+			return false
+		}
+		if b < a {
+			a = b
+		}
+		min[i] = a
+	}
+	// A digit vector not less than min has a checksum sum not greater
+	// than the sum for min; count vectors for each such sum, for which
+	// the checksum digits are not less than the revealed ones.
+	var (
+		maxSum   int   // checksum sum of the minimum message digits
+		headroom []int // how much each message digit can be increased
+		total    int   // sum of headroom
+	)
+	minMessage, minChecksum := s.splitDigits(min)
+	for _, v := range minMessage {
+		maxSum += s.chainLen - v
+		if h := s.chainLen - 1 - v; h > 0 {
+			headroom = append(headroom, h)
+			total += h
+		}
+	}
+	sumA, sumB := s.checksumSum(digitsA), s.checksumSum(digitsB)
+	for d := 0; d <= total; d++ {
+		sum := maxSum - d
+		if !s.checksumCovers(sum, minChecksum) {
+			continue
+		}
+		signed := 0 // signed vectors with this sum
+		if sum == sumA {
+			signed++
+		}
+		if sum == sumB && !equalDigits(digitsA, digitsB) {
+			signed++
+		}
+		if countIncrements(headroom, total, d) > signed {
+			return true
+		}
+	}
+	return false
+}
+
+// checksumSum returns the checksum sum of message digits in digits.
+func (s *Scheme) checksumSum(digits []int) int {
+	sum := 0
+	message, _ := s.splitDigits(digits)
+	for _, v := range message {
+		sum += s.chainLen - v
+	}
+	return sum
+}
+
+// checksumCovers reports whether each checksum digit of sum is not less
+// than the corresponding digit in min.
+func (s *Scheme) checksumCovers(sum int, min []int) bool {
+	for i, v := range min {
+		shift := uint((len(min) - 1 - i) * s.w)
+		if sum>>shift&(s.chainLen-1) < v {
+			return false
+		}
+	}
+	return true
+}
+
+// countIncrements returns the number of ways, capped at 3, to distribute d
+// increments over digits with the given headroom, which sums to total.
+func countIncrements(headroom []int, total, d int) int {
+	switch {
+	case d < 0 || d > total:
+		return 0
+	case d == 0 || d == total || len(headroom) == 1:
+		return 1
+	case len(headroom) == 2:
+		lo, hi := d-headroom[1], d
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > headroom[0] {
+			hi = headroom[0]
+		}
+		if n := hi - lo + 1; n < 3 {
+			return n
+		}
+	}
+	// With at least three digits that can be increased, there are at
+	// least three ways to distribute any number of increments between
+	// zero and total exclusive.
+	return 3
+}
+
+// equalDigits reports whether digit vectors of the same length are equal.
+func equalDigits(a, b []int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifyCounted is like Verify, but also returns the number of chain hash
+// evaluations performed, which depends on the message digest: each chain
+// is hashed 1<<w minus its digit times, so the number is between
+// NumChains and NumChains<<w. If the public key or signature size is
+// wrong, it returns false and 0.
+func (s *Scheme) VerifyCounted(publicKey PublicKey, message, sig []byte) (ok bool, hashOps int) {
+	if !s.checkVerifySizes(publicKey, sig) {
+		return false, 0
+	}
+	digits := s.messageDigits(sig[:s.randLen], message)
+	for _, v := range digits {
+		hashOps += s.chainLen - v
+	}
+	return s.verifyDigits(publicKey, digits, sig), hashOps
+}
+
+// VerifyWitness is like Verify, but also returns chain endpoints recomputed
+// from the signature, in chain order, as a witness: they are the input from
+// which the public key is computed with the message hash function, so a
+// third party can check the witness against the public key without hashing
+// chains. By default the public key is the hash of the concatenated
+// endpoints; in the L-tree mode it's the root of the L-tree over the
+// endpoints, whose nodes are hashed with the salt from the public key. With
+// WithParamBinding, the parameter tag is also hashed: before the endpoints,
+// or after the salt in each L-tree node. Endpoints are returned for invalid
+// signatures too; if the public key or signature size is wrong, it returns
+// false and nil.
+func (s *Scheme) VerifyWitness(publicKey PublicKey, message, sig []byte) (ok bool, endpoints [][]byte) {
+	if !s.checkVerifySizes(publicKey, sig) {
+		return false, nil
+	}
+	salt, key := s.splitPublicKey(publicKey)
+	digits := s.messageDigits(sig[:s.randLen], message)
+	buf := s.newEndpoints()
+	blockHash := s.chainFunc()
+	chains := sig[s.randLen:]
+	for i, v := range digits {
+		buf = s.appendChain(buf, blockHash, chains[:s.blockSize], i, v, s.chainLen-v)
+		chains = chains[s.blockSize:]
+	}
+	keyHash := s.newKeyHasher(salt)
+	keyHash.addEndpoints(buf, s.blockSize)
+	endpoints = make([][]byte, len(digits))
+	for i := range endpoints {
+		endpoints[i] = buf[i*s.blockSize : (i+1)*s.blockSize : (i+1)*s.blockSize]
+	}
+	ok = bytes.Equal(keyHash.sum(), key)
+	if !ok {
+		s.observeVerify(VerifyMismatch)
+	}
+	return ok, endpoints
+}
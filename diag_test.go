@@ -0,0 +1,210 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestVerifyDistance(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, diffs := otssha256.VerifyDistance(pub, msg, sig)
+	if !ok || diffs != 0 {
+		t.Fatalf("correct signature: expected true, 0; got %v, %d", ok, diffs)
+	}
+	sig[len(sig)-1] ^= 1
+	ok, diffs = otssha256.VerifyDistance(pub, msg, sig)
+	if ok || diffs == 0 {
+		t.Fatalf("corrupted signature: expected false, nonzero; got %v, %d", ok, diffs)
+	}
+	ok, diffs = otssha256.VerifyDistance(pub, msg, sig[1:])
+	if ok || diffs != -1 {
+		t.Fatalf("wrong signature size: expected false, -1; got %v, %d", ok, diffs)
+	}
+}
+
+func TestRevealedBytesFor(t *testing.T) {
+	s := otssha256
+	r := make([]byte, s.RandomizerLen())
+	digits, err := s.MessageDigits(r, []byte(testMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := s.RevealedBytesFor(digits)
+	max := s.numChains * s.chainLen * s.blockSize
+	if n <= 0 || n >= max {
+		t.Fatalf("expected revealed bytes in (0, %d), got %d", max, n)
+	}
+	zeros := make([]int, len(digits))
+	if got := s.RevealedBytesFor(zeros); got != max {
+		t.Fatalf("all zero digits: expected %d, got %d", max, got)
+	}
+	top := make([]int, len(digits))
+	for i := range top {
+		top[i] = s.chainLen - 1
+	}
+	if got := s.RevealedBytesFor(top); got != s.numChains*s.blockSize {
+		t.Fatalf("all top digits: expected %d, got %d", s.numChains*s.blockSize, got)
+	}
+	if got := s.RevealedBytesFor(digits[1:]); got != -1 {
+		t.Fatalf("wrong number of digits: expected -1, got %d", got)
+	}
+	zeros[0] = s.chainLen
+	if got := s.RevealedBytesFor(zeros); got != -1 {
+		t.Fatalf("digit out of range: expected -1, got %d", got)
+	}
+}
+
+func TestRecoverMessageDigest(t *testing.T) {
+	msg := []byte(testMessage)
+	var digests [][]byte
+	for i := 0; i < 2; i++ {
+		priv, _, err := otssha256.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := otssha256.Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digests = append(digests, otssha256.RecoverMessageDigest(sig, msg))
+	}
+	expected := sha256.Sum256(msg)
+	for i, d := range digests {
+		if !bytes.Equal(d, expected[:]) {
+			t.Fatalf("%d: expected %x, got %x", i, expected, d)
+		}
+	}
+	if d := otssha256.RecoverMessageDigest(nil, msg); d != nil {
+		t.Fatalf("expected nil for wrong signature size, got %x", d)
+	}
+}
+
+func TestForgeableAfterReuse(t *testing.T) {
+	s := otssha256
+	r := make([]byte, s.RandomizerLen())
+	digitsA, err := s.MessageDigits(r, []byte("first message"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	digitsB, err := s.MessageDigits(r, []byte("second message"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.ForgeableAfterReuse(digitsA, digitsB) {
+		t.Fatalf("expected signatures of two messages to allow forgery")
+	}
+	if s.ForgeableAfterReuse(digitsA, digitsA) {
+		t.Fatalf("expected a single signature not to allow forgery")
+	}
+	if s.ForgeableAfterReuse(digitsA, digitsB[1:]) {
+		t.Fatalf("expected false for wrong number of digits")
+	}
+}
+
+func TestCountIncrements(t *testing.T) {
+	tests := []struct {
+		headroom []int
+		d, n     int
+	}{
+		{nil, 0, 1},
+		{nil, 1, 0},
+		{[]int{5}, 3, 1},
+		{[]int{1, 1}, 1, 2},
+		{[]int{1, 1}, 2, 1},
+		{[]int{3, 1}, 2, 2},
+		{[]int{3, 3}, 3, 3},
+		{[]int{1, 1, 1}, 2, 3},
+	}
+	for _, test := range tests {
+		total := 0
+		for _, h := range test.headroom {
+			total += h
+		}
+		if n := countIncrements(test.headroom, total, test.d); n != test.n {
+			t.Errorf("%v, %d: expected %d, got %d", test.headroom, test.d, test.n, n)
+		}
+	}
+}
+
+func TestVerifyCounted(t *testing.T) {
+	s := otssha256
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, ops := s.VerifyCounted(pub, msg, sig)
+	if !ok {
+		t.Fatalf("failed to verify correct signature")
+	}
+	min, max := s.NumChains(), s.NumChains()<<uint(s.w)
+	if ops < min || ops > max {
+		t.Fatalf("expected hash operations in [%d, %d], got %d", min, max, ops)
+	}
+	digits, err := s.MessageDigits(sig[:s.RandomizerLen()], msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := 0
+	for _, v := range digits {
+		expected += s.chainLen - v
+	}
+	if ops != expected {
+		t.Fatalf("expected %d hash operations, got %d", expected, ops)
+	}
+	if ok, ops := s.VerifyCounted(pub, msg[1:], sig); ok || ops < min || ops > max {
+		t.Fatalf("wrong message: expected false and count in range, got %v, %d", ok, ops)
+	}
+	if ok, ops := s.VerifyCounted(pub, msg, sig[1:]); ok || ops != 0 {
+		t.Fatalf("wrong signature size: expected false, 0; got %v, %d", ok, ops)
+	}
+}
+
+func TestVerifyWitness(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, endpoints := otssha256.VerifyWitness(pub, msg, sig)
+	if !ok {
+		t.Fatalf("failed to verify correct signature")
+	}
+	if len(endpoints) != otssha256.NumChains() {
+		t.Fatalf("expected %d endpoints, got %d", otssha256.NumChains(), len(endpoints))
+	}
+	h := sha256.New()
+	for _, e := range endpoints {
+		h.Write(e)
+	}
+	if !bytes.Equal(h.Sum(nil), pub) {
+		t.Fatalf("folded endpoints don't match public key")
+	}
+	if ok, endpoints := otssha256.VerifyWitness(pub, msg[1:], sig); ok || len(endpoints) != otssha256.NumChains() {
+		t.Fatalf("wrong message: expected false with endpoints, got %v, %d endpoints", ok, len(endpoints))
+	}
+	if ok, endpoints := otssha256.VerifyWitness(pub, msg, sig[1:]); ok || endpoints != nil {
+		t.Fatalf("wrong signature size: expected false, nil; got %v, %v", ok, endpoints)
+	}
+}
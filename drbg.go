@@ -0,0 +1,112 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"sync"
+)
+
+// MinDRBGSeedSize is the minimum size of HashDRBG seed and reseed entropy.
+const MinDRBGSeedSize = 32
+
+var zeroBlock [sha256.Size]byte
+
+var errShortDRBGSeed = errors.New("wots: DRBG seed is too short")
+
+// HashDRBG is a deterministic random bit generator based on HMAC-SHA256,
+// following HMAC_DRBG from NIST SP 800-90A without personalization
+// strings and reseed counter. It can be used as a random byte reader of
+// a scheme on platforms where crypto/rand is unavailable, but a secret
+// random seed is.
+//
+// HashDRBG is safe for concurrent use by multiple goroutines.
+type HashDRBG struct {
+	mu  sync.Mutex
+	k   []byte
+	v   []byte
+	err error // errShortDRBGSeed until seeded with enough entropy
+}
+
+// NewHashDRBG returns a new generator instantiated with the given seed,
+// which must be secret, random, and at least MinDRBGSeedSize bytes long.
+// Generators with the same seed produce the same bytes. If the seed is too
+// short, Read returns an error until the generator is reseeded.
+func NewHashDRBG(seed []byte) *HashDRBG {
+	d := &HashDRBG{
+		k: make([]byte, sha256.Size),
+		v: make([]byte, sha256.Size),
+	}
+	for i := range d.v {
+		d.v[i] = 0x01
+	}
+	if len(seed) < MinDRBGSeedSize {
+		d.err = errShortDRBGSeed
+		return d
+	}
+	d.update(seed)
+	return d
+}
+
+// Reseed mixes the given entropy, which must be at least MinDRBGSeedSize
+// bytes long, into the generator state. It returns an error and leaves
+// the state unchanged if the entropy is too short.
+func (d *HashDRBG) Reseed(entropy []byte) error {
+	if len(entropy) < MinDRBGSeedSize {
+		return errShortDRBGSeed
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.update(entropy)
+	d.err = nil
+	return nil
+}
+
+// Read fills p with generated bytes. It returns an error only if the
+// generator wasn't seeded with enough entropy.
+func (d *HashDRBG) Read(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return 0, d.err
+	}
+	n := len(p)
+	for len(p) > 0 {
+		d.v = d.hmac(d.v)
+		// An all-zero block has negligible probability, but would trip
+		// weak key checks, so it's never returned.
+		if bytes.Equal(d.v, zeroBlock[:]) {
+			continue
+		}
+		p = p[copy(p, d.v):]
+	}
+	// Update state after each request for backtracking resistance.
+	d.update(nil)
+	return n, nil
+}
+
+// update is HMAC_DRBG update function.
+func (d *HashDRBG) update(data []byte) {
+	d.k = d.hmac(d.v, []byte{0x00}, data)
+	d.v = d.hmac(d.v)
+	if data == nil {
+		return
+	}
+	d.k = d.hmac(d.v, []byte{0x01}, data)
+	d.v = d.hmac(d.v)
+}
+
+// hmac returns HMAC-SHA256 of the concatenation of parts with the current
+// key.
+func (d *HashDRBG) hmac(parts ...[]byte) []byte {
+	mac := hmac.New(sha256.New, d.k)
+	for _, p := range parts {
+		mac.Write(p)
+	}
+	return mac.Sum(nil)
+}
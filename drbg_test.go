@@ -0,0 +1,91 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"testing"
+)
+
+func TestHashDRBG(t *testing.T) {
+	read := func(r io.Reader, n int) []byte {
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	d1, d2 := NewHashDRBG(testSeed), NewHashDRBG(testSeed)
+	// Different read sizes must not affect the first bytes.
+	a := read(d1, 100)
+	b := read(d2, 1000)
+	if !bytes.Equal(a, b[:100]) {
+		t.Fatalf("streams with the same seed differ")
+	}
+	if bytes.Equal(read(NewHashDRBG(testSeed), 64), read(NewHashDRBG(make([]byte, 32)), 64)) {
+		t.Fatalf("streams with different seeds are equal")
+	}
+	if bytes.Equal(read(d1, 32), read(d1, 32)) {
+		t.Fatalf("stream is constant")
+	}
+
+	// Distribution smoke check: each byte value must occur in 64 KiB,
+	// and the number of set bits must be close to a half.
+	buf := read(NewHashDRBG(testSeed), 1<<16)
+	var counts [256]int
+	bits := 0
+	for _, c := range buf {
+		counts[c]++
+		for ; c != 0; c &= c - 1 {
+			bits++
+		}
+	}
+	for v, n := range counts {
+		if n < 128 || n > 384 {
+			t.Fatalf("byte %d occurs %d times", v, n)
+		}
+	}
+	if total := len(buf) * 8; bits < total/2-total/100 || bits > total/2+total/100 {
+		t.Fatalf("%d of %d bits set", bits, total)
+	}
+
+	// Reseeding changes the stream.
+	d1, d2 = NewHashDRBG(testSeed), NewHashDRBG(testSeed)
+	if err := d1.Reseed(testSeed); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(read(d1, 32), read(d2, 32)) {
+		t.Fatalf("reseeding didn't change the stream")
+	}
+	if err := d1.Reseed(testSeed[:16]); err == nil {
+		t.Fatalf("no error for short reseed entropy")
+	}
+
+	// Short seed.
+	d := NewHashDRBG(testSeed[:16])
+	if _, err := d.Read(make([]byte, 1)); err == nil {
+		t.Fatalf("no error for short seed")
+	}
+	if err := d.Reseed(testSeed); err != nil {
+		t.Fatal(err)
+	}
+	read(d, 1)
+
+	// Usable as scheme random byte reader.
+	s := NewScheme(sha256.New, NewHashDRBG(testSeed))
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := s.Sign(priv, []byte(testMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Verify(pub, []byte(testMessage), sig) {
+		t.Fatalf("failed to verify")
+	}
+}
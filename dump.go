@@ -0,0 +1,45 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// DumpSignature returns a human-readable dump of the signature for
+// debugging: the randomization parameter in hex, followed by a line per
+// chain with the chain index, whether the chain encodes a message digest
+// or a checksum digit, and the chain block in hex. The dump is stable, but
+// not intended for parsing. If the signature size is wrong, the dump only
+// reports the size mismatch.
+func DumpSignature(s *Scheme, sig []byte) string {
+	var b strings.Builder
+	if len(sig) != s.SignatureSize() {
+		fmt.Fprintf(&b, "invalid signature size %d, expected %d\n", len(sig), s.SignatureSize())
+		return b.String()
+	}
+	if s.randLen == 0 {
+		b.WriteString("randomizer none\n")
+	} else {
+		fmt.Fprintf(&b, "randomizer %s\n", hex.EncodeToString(sig[:s.randLen]))
+	}
+	blocks := sig[s.randLen:]
+	// Index range of checksum chains.
+	csStart, csEnd := s.numDigits, s.numChains
+	if s.csFirst {
+		csStart, csEnd = 0, s.numChains-s.numDigits
+	}
+	for i := 0; i < s.numChains; i++ {
+		kind := "message"
+		if i >= csStart && i < csEnd {
+			kind = "checksum"
+		}
+		block := blocks[i*s.blockSize : (i+1)*s.blockSize]
+		fmt.Fprintf(&b, "chain %d %s %s\n", i, kind, hex.EncodeToString(block))
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"crypto/sha256"
+	"strings"
+	"testing"
+)
+
+func TestDumpSignature(t *testing.T) {
+	for _, s := range []*Scheme{
+		otssha256,
+		NewScheme(sha256.New, zeroReader, WithChecksumFirst()),
+		NewScheme(sha256.New, zeroReader, WithoutRandomizedHashing()),
+	} {
+		priv, _, err := s.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := s.Sign(priv, []byte(testMessage))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(DumpSignature(s, sig), "\n"), "\n")
+		if len(lines) != 1+s.NumChains() {
+			t.Fatalf("expected %d lines, got %d", 1+s.NumChains(), len(lines))
+		}
+		if !strings.HasPrefix(lines[0], "randomizer ") {
+			t.Fatalf("bad first line: %q", lines[0])
+		}
+		var message, checksum int
+		for _, line := range lines[1:] {
+			switch {
+			case strings.Contains(line, " message "):
+				message++
+			case strings.Contains(line, " checksum "):
+				checksum++
+			default:
+				t.Fatalf("bad chain line: %q", line)
+			}
+		}
+		if message != 32 || checksum != s.NumChains()-32 {
+			t.Fatalf("wrong chain kinds: %d message, %d checksum", message, checksum)
+		}
+		first := "chain 0 message "
+		if s.csFirst {
+			first = "chain 0 checksum "
+		}
+		if !strings.HasPrefix(lines[1], first) {
+			t.Fatalf("bad chain line: %q", lines[1])
+		}
+		// Must not panic.
+		if d := DumpSignature(s, sig[1:]); !strings.HasPrefix(d, "invalid signature size") {
+			t.Fatalf("bad dump for wrong size: %q", d)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+)
+
+// Errors wrapped by ParseError.
+var (
+	ErrWrongSize          = errors.New("wots: wrong size")
+	ErrInvalidEncoding    = errors.New("wots: invalid encoding")
+	ErrTruncated          = errors.New("wots: input is truncated")
+	ErrUnsupportedVersion = errors.New("wots: unsupported version")
+	ErrChecksum           = errors.New("wots: checksum mismatch")
+)
+
+// ParseError describes a failure to parse serialized input.
+// It wraps one of ErrWrongSize, ErrInvalidEncoding, ErrTruncated,
+// ErrUnsupportedVersion, or ErrChecksum, which can be checked with
+// errors.Is.
+type ParseError struct {
+	Offset int    // byte offset in input where the error was detected
+	Reason string // description of the error
+	Err    error  // underlying error
+}
+
+func (e *ParseError) Error() string {
+	return "wots: " + e.Reason + " at offset " + strconv.Itoa(e.Offset)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// Signature represents a signature.
+type Signature []byte
+
+// isPublicKeySize reports whether n is a public key size of some scheme.
+func isPublicKeySize(n int) bool {
+	return isHashSize(n) || (n%2 == 0 && isHashSize(n/2)) // with L-tree salt
+}
+
+// Bounds of the number of chains of supported schemes.
+var minChains, maxChains = chainCountRange()
+
+// chainCountRange returns the minimum and maximum number of chains of
+// supported schemes.
+func chainCountRange() (lo, hi int) {
+	for d := MinHashSize; d <= MaxHashSize; d++ {
+		for w := 1; w <= 16; w *= 2 {
+			if d*8%w != 0 {
+				continue
+			}
+			_, chains := chainCounts(d, w)
+			if lo == 0 || chains < lo {
+				lo = chains
+			}
+			if chains > hi {
+				hi = chains
+			}
+		}
+	}
+	return lo, hi
+}
+
+// isPrivateKeySize reports whether n is within the range of private key
+// sizes of supported schemes. Since almost every size in the range is
+// a private key size of some scheme, it doesn't check n further.
+func isPrivateKeySize(n int) bool {
+	return n >= minChains*MinHashSize && n <= maxChains*MaxHashSize
+}
+
+// isSignatureSize reports whether n is within the range of signature
+// sizes of supported schemes. Like isPrivateKeySize, it doesn't check n
+// further.
+func isSignatureSize(n int) bool {
+	return n >= minChains*MinHashSize && n <= MaxHashSize+maxChains*MaxHashSize
+}
+
+// decodeText decodes base64-encoded text, checking its decoded size.
+func decodeText(text []byte, validSize func(int) bool, what string) ([]byte, error) {
+	b := make([]byte, base64.StdEncoding.DecodedLen(len(text)))
+	n, err := base64.StdEncoding.Decode(b, text)
+	if err != nil {
+		offset := n
+		if e, ok := err.(base64.CorruptInputError); ok {
+			offset = int(e)
+		}
+		return nil, &ParseError{Offset: offset, Reason: "invalid base64 " + what, Err: ErrInvalidEncoding}
+	}
+	if !validSize(n) {
+		// Offset is the decoded size.
+		return nil, &ParseError{Offset: n, Reason: "wrong " + what + " size", Err: ErrWrongSize}
+	}
+	return b[:n], nil
+}
+
+func encodeText(b []byte) []byte {
+	text := make([]byte, base64.StdEncoding.EncodedLen(len(b)))
+	base64.StdEncoding.Encode(text, b)
+	return text
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns the public key
+// encoded in standard base64 encoding.
+func (k PublicKey) MarshalText() ([]byte, error) {
+	return encodeText(k), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It decodes the public
+// key from standard base64 encoding. It checks that the size is a public
+// key size of some supported scheme; use Scheme.ParsePublicKey to check it
+// against a particular scheme.
+func (k *PublicKey) UnmarshalText(text []byte) error {
+	b, err := decodeText(text, isPublicKeySize, "public key")
+	if err != nil {
+		return err
+	}
+	*k = b
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns the private key
+// encoded in standard base64 encoding.
+func (k PrivateKey) MarshalText() ([]byte, error) {
+	return encodeText(k), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It decodes the private
+// key from standard base64 encoding. Since the scheme is unknown, it only
+// checks that the size is within the range of private key sizes of
+// supported schemes; use Scheme.ParsePrivateKey to check it exactly.
+func (k *PrivateKey) UnmarshalText(text []byte) error {
+	b, err := decodeText(text, isPrivateKeySize, "private key")
+	if err != nil {
+		return err
+	}
+	*k = b
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns the signature
+// encoded in standard base64 encoding.
+func (sig Signature) MarshalText() ([]byte, error) {
+	return encodeText(sig), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It decodes the
+// signature from standard base64 encoding. Since the scheme is unknown, it
+// only checks that the size is within the range of signature sizes of
+// supported schemes; use Scheme.ParseSignature to check it exactly.
+func (sig *Signature) UnmarshalText(text []byte) error {
+	b, err := decodeText(text, isSignatureSize, "signature")
+	if err != nil {
+		return err
+	}
+	*sig = b
+	return nil
+}
+
+// ParsePublicKey decodes the public key of the scheme from standard base64
+// encoding. It returns a ParseError if the text is not valid base64, or the
+// decoded size doesn't match the scheme.
+func (s *Scheme) ParsePublicKey(text []byte) (PublicKey, error) {
+	return decodeText(text, func(n int) bool { return n == s.PublicKeySize() }, "public key")
+}
+
+// ParsePrivateKey is like ParsePublicKey, but decodes the private key.
+func (s *Scheme) ParsePrivateKey(text []byte) (PrivateKey, error) {
+	return decodeText(text, func(n int) bool { return n == s.PrivateKeySize() }, "private key")
+}
+
+// ParseSignature is like ParsePublicKey, but decodes the signature.
+func (s *Scheme) ParseSignature(text []byte) (Signature, error) {
+	return decodeText(text, func(n int) bool { return n == s.SignatureSize() }, "signature")
+}
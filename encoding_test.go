@@ -0,0 +1,184 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"flag"
+	"testing"
+)
+
+func TestTextRoundTrip(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := pub.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pub2 PublicKey
+	if err := pub2.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pub, pub2) {
+		t.Fatalf("public key: expected %x, got %x", pub, pub2)
+	}
+
+	text, err = priv.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var priv2 PrivateKey
+	if err := priv2.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priv, priv2) {
+		t.Fatalf("private key: expected %x, got %x", priv, priv2)
+	}
+
+	text, err = Signature(sig).MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sig2 Signature
+	if err := sig2.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if !otssha256.Verify(pub2, msg, sig2) {
+		t.Fatalf("failed to verify decoded signature")
+	}
+}
+
+func TestUnmarshalTextErrors(t *testing.T) {
+	var pub PublicKey
+	var priv PrivateKey
+	var sig Signature
+	tests := []struct {
+		v    encoding.TextUnmarshaler
+		text string
+	}{
+		{&pub, "not base64!"},
+		{&pub, ""},
+		{&pub, "AAAA"},
+		{&priv, "not base64!"},
+		{&priv, testPublicKey},
+		{&sig, "not base64!"},
+		{&sig, testPublicKey},
+	}
+	for i, test := range tests {
+		if err := test.v.UnmarshalText([]byte(test.text)); err == nil {
+			t.Errorf("%d: expected error for %q", i, test.text)
+		}
+	}
+	if err := pub.UnmarshalText([]byte(testPublicKey)); err != nil {
+		t.Errorf("public key: %s", err)
+	}
+	if err := sig.UnmarshalText([]byte(testSig)); err != nil {
+		t.Errorf("signature: %s", err)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	var pub PublicKey
+	var sig Signature
+	envelope, err := otssha256.Envelope(make([]byte, otssha256.SignatureSize()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	badVersion := append([]byte{2}, envelope[1:]...)
+	tests := []struct {
+		name   string
+		err    error
+		offset int
+		target error
+	}{
+		{"base64", pub.UnmarshalText([]byte("AAAA!AAA")), 4, ErrInvalidEncoding},
+		{"public key size", pub.UnmarshalText([]byte("AAAA")), 3, ErrWrongSize},
+		{"signature size", sig.UnmarshalText([]byte(testPublicKey)), 32, ErrWrongSize},
+		{"envelope header", func() error { _, _, err := openEnvelope(envelope[:1]); return err }(), 1, ErrTruncated},
+		{"envelope version", func() error { _, _, err := openEnvelope(badVersion); return err }(), 0, ErrUnsupportedVersion},
+		{"envelope ID", func() error { _, _, err := openEnvelope(envelope[:5]); return err }(), 5, ErrTruncated},
+		{"sealed", func() error { _, err := otssha256.OpenSealed(testKDF, nil, make([]byte, 10)); return err }(), 10, ErrWrongSize},
+	}
+	for _, test := range tests {
+		var pe *ParseError
+		if !errors.As(test.err, &pe) {
+			t.Errorf("%s: expected ParseError, got %v", test.name, test.err)
+			continue
+		}
+		if pe.Offset != test.offset {
+			t.Errorf("%s: expected offset %d, got %d", test.name, test.offset, pe.Offset)
+		}
+		if !errors.Is(test.err, test.target) {
+			t.Errorf("%s: error %v doesn't wrap %v", test.name, test.err, test.target)
+		}
+	}
+}
+
+func TestTextVar(t *testing.T) {
+	var pub PublicKey
+	var sig Signature
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.TextVar(&pub, "key", PublicKey(nil), "public key")
+	fs.TextVar(&sig, "sig", Signature(nil), "signature")
+	if err := fs.Parse([]string{"-key", testPublicKey, "-sig", testSig}); err != nil {
+		t.Fatal(err)
+	}
+	if !otssha256.Verify(pub, []byte(testMessage), sig) {
+		t.Fatalf("failed to verify signature from flags")
+	}
+	fs.SetOutput(new(bytes.Buffer))
+	if err := fs.Parse([]string{"-key", "AAAA"}); err == nil {
+		t.Fatalf("expected error for wrong public key size")
+	}
+}
+
+func TestUnmarshalTextSizeRange(t *testing.T) {
+	var priv PrivateKey
+	var sig Signature
+	for _, n := range []int{1, 32, minChains*MinHashSize - 1, maxChains*MaxHashSize + MaxHashSize + 1} {
+		text := encodeText(make([]byte, n))
+		if err := sig.UnmarshalText(text); !errors.Is(err, ErrWrongSize) {
+			t.Errorf("signature size %d: expected ErrWrongSize, got %v", n, err)
+		}
+		if err := priv.UnmarshalText(text); !errors.Is(err, ErrWrongSize) {
+			t.Errorf("private key size %d: expected ErrWrongSize, got %v", n, err)
+		}
+	}
+}
+
+func TestParseWithScheme(t *testing.T) {
+	s := otssha256
+	for _, test := range []struct {
+		name  string
+		size  int
+		parse func([]byte) error
+	}{
+		{"public key", s.PublicKeySize(), func(b []byte) error { _, err := s.ParsePublicKey(b); return err }},
+		{"private key", s.PrivateKeySize(), func(b []byte) error { _, err := s.ParsePrivateKey(b); return err }},
+		{"signature", s.SignatureSize(), func(b []byte) error { _, err := s.ParseSignature(b); return err }},
+	} {
+		if err := test.parse(encodeText(make([]byte, test.size))); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+		for _, n := range []int{0, test.size - 1, test.size + 1, 500, 1000, 2000, 2177, 5000} {
+			if err := test.parse(encodeText(make([]byte, n))); !errors.Is(err, ErrWrongSize) {
+				t.Errorf("%s size %d: expected ErrWrongSize, got %v", test.name, n, err)
+			}
+		}
+		if err := test.parse([]byte("not base64!")); !errors.Is(err, ErrInvalidEncoding) {
+			t.Errorf("%s: expected ErrInvalidEncoding, got %v", test.name, err)
+		}
+	}
+}
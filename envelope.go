@@ -0,0 +1,65 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "errors"
+
+// envelopeVersion is the version of signature envelope format.
+const envelopeVersion = 1
+
+// Envelope returns the self-describing signature envelope, which consists
+// of the format version, the scheme ID, and the signature:
+//
+//	version (1 byte) ‖ len(id) (1 byte) ‖ id ‖ signature
+//
+// It returns an error if the scheme has no ID or the signature size
+// doesn't match the scheme.
+func (s *Scheme) Envelope(sig []byte) ([]byte, error) {
+	id := s.ID()
+	if id == "" {
+		return nil, errors.New("wots: scheme has no ID")
+	}
+	if len(id) > 255 {
+		return nil, errors.New("wots: scheme ID is too long")
+	}
+	if len(sig) != s.SignatureSize() {
+		return nil, errors.New("wots: signature size doesn't match the scheme")
+	}
+	out := make([]byte, 0, 2+len(id)+len(sig))
+	out = append(out, envelopeVersion, byte(len(id)))
+	out = append(out, id...)
+	return append(out, sig...), nil
+}
+
+// openEnvelope parses the envelope and returns the scheme ID and the signature.
+func openEnvelope(envelope []byte) (id string, sig []byte, err error) {
+	if len(envelope) < 2 {
+		return "", nil, &ParseError{Offset: len(envelope), Reason: "envelope header is truncated", Err: ErrTruncated}
+	}
+	if envelope[0] != envelopeVersion {
+		return "", nil, &ParseError{Offset: 0, Reason: "unsupported envelope version", Err: ErrUnsupportedVersion}
+	}
+	n := int(envelope[1])
+	if len(envelope) < 2+n {
+		return "", nil, &ParseError{Offset: len(envelope), Reason: "envelope scheme ID is truncated", Err: ErrTruncated}
+	}
+	return string(envelope[2 : 2+n]), envelope[2+n:], nil
+}
+
+// VerifyEnvelope verifies the signature of message in the envelope
+// returned by Envelope using the public key, and returns true iff the
+// signature is valid. It returns an error if the envelope is malformed, or
+// its scheme ID doesn't match the scheme's ID, which prevents verifying
+// signatures re-tagged for a different scheme.
+func (s *Scheme) VerifyEnvelope(publicKey PublicKey, message, envelope []byte) (bool, error) {
+	id, sig, err := openEnvelope(envelope)
+	if err != nil {
+		return false, err
+	}
+	if want := s.ID(); want == "" || id != want {
+		return false, errors.New("wots: envelope scheme ID doesn't match")
+	}
+	return s.Verify(publicKey, message, sig), nil
+}
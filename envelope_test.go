@@ -0,0 +1,66 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestVerifyEnvelope(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := otssha256.Envelope(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := otssha256.VerifyEnvelope(pub, msg, envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("signature verification failed")
+	}
+	ok, err = otssha256.VerifyEnvelope(pub, []byte("wrong message"), envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf("verified signature for wrong message")
+	}
+
+	// Re-tagged for a different scheme with the same signature size.
+	other := NewScheme(sha256.New, rand.Reader, WithRandHash(RandHashPrefix))
+	retagged, err := other.Envelope(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := otssha256.VerifyEnvelope(pub, msg, retagged); err == nil {
+		t.Fatalf("no error for mismatched scheme ID")
+	}
+	if _, err := other.VerifyEnvelope(pub, msg, envelope); err == nil {
+		t.Fatalf("no error for mismatched scheme ID")
+	}
+
+	bad := append([]byte(nil), envelope...)
+	bad[0] = 2
+	if _, err := otssha256.VerifyEnvelope(pub, msg, bad); err == nil {
+		t.Fatalf("no error for wrong version")
+	}
+	if _, err := otssha256.VerifyEnvelope(pub, msg, envelope[:5]); err == nil {
+		t.Fatalf("no error for truncated envelope")
+	}
+	if _, err := otssha256.Envelope(sig[1:]); err == nil {
+		t.Fatalf("no error for wrong signature size")
+	}
+}
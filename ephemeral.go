@@ -0,0 +1,64 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// GenerateAndSign generates an ephemeral key pair, signs the message with
+// its private key, and returns the public key and the signature. The
+// private key is wiped from memory after signing and is never exposed, so
+// it can't be accidentally reused.
+//
+// Randomness for the private key and the randomization parameter is read
+// from the scheme's random reader at once.
+func (s *Scheme) GenerateAndSign(message []byte) (publicKey PublicKey, sig []byte, err error) {
+	if !s.validHashSizes() {
+		return nil, nil, errors.New("wots: wrong hash output size")
+	}
+	buf := make([]byte, s.PrivateKeySize()+s.randLen)
+	defer func() {
+		for i := range buf {
+			buf[i] = 0
+		}
+	}()
+	if _, err := io.ReadFull(s.rand, buf); err != nil {
+		return nil, nil, err
+	}
+	privateKey, r := PrivateKey(buf[:s.PrivateKeySize()]), buf[s.PrivateKeySize():]
+	publicKey, err = s.PublicKeyFromPrivate(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	sig = s.newSigner().signWithRandomizer(make([]byte, 0, s.SignatureSize()), privateKey, r, message)
+	return publicKey, sig, nil
+}
+
+// ErrSelfVerification is returned by SignSealAndDestroy if the public key
+// recovered from the created signature doesn't match the generated one,
+// which indicates a hardware or software fault during signing.
+var ErrSelfVerification = errors.New("wots: signature self-verification failed")
+
+// SignSealAndDestroy is like GenerateAndSign, but additionally checks that
+// the public key recovered from the signature, as with RecoverPublicKey,
+// matches the generated public key before returning them. Unlike
+// RecoverPublicKey, it also works in L-tree mode. If the produced
+// signature does not verify against the public key, it returns
+// ErrSelfVerification and the private key is still destroyed; the faulty
+// signature, which could leak private key chain values, is never exposed.
+func (s *Scheme) SignSealAndDestroy(message []byte) (publicKey PublicKey, sig []byte, err error) {
+	publicKey, sig, err = s.GenerateAndSign(message)
+	if err != nil {
+		return nil, nil, err
+	}
+	salt, key := s.splitPublicKey(publicKey)
+	if !bytes.Equal(s.recoverMessageKey(nil, salt, message, sig), key) {
+		return nil, nil, ErrSelfVerification
+	}
+	return publicKey, sig, nil
+}
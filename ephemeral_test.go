@@ -0,0 +1,77 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+	"testing"
+)
+
+type countingReader struct {
+	r     io.Reader
+	calls int
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	cr.calls++
+	return cr.r.Read(p)
+}
+
+func TestGenerateAndSign(t *testing.T) {
+	cr := &countingReader{r: rand.Reader}
+	s := NewScheme(sha256.New, cr)
+	msg := []byte(testMessage)
+	pub, sig, err := s.GenerateAndSign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cr.calls != 1 {
+		t.Fatalf("expected 1 read, got %d", cr.calls)
+	}
+	if len(pub) != s.PublicKeySize() {
+		t.Fatalf("expected public key size %d, got %d", s.PublicKeySize(), len(pub))
+	}
+	if !s.Verify(pub, msg, sig) {
+		t.Fatalf("signature verification failed")
+	}
+	if s.Verify(pub, []byte("wrong message"), sig) {
+		t.Fatalf("verified signature for wrong message")
+	}
+}
+
+// faultyChainHasher is a chain hasher, which corrupts chain values
+// computed during signing.
+type faultyChainHasher struct{ ChainHasher }
+
+func (c faultyChainHasher) Chain(in []byte, start, steps int, addr []byte) []byte {
+	out := c.ChainHasher.Chain(in, start, steps, addr)
+	if start == 0 && steps > 0 && steps < 255 {
+		out[0] ^= 1
+	}
+	return out
+}
+
+func TestSignSealAndDestroy(t *testing.T) {
+	msg := []byte(testMessage)
+	for _, s := range []*Scheme{otssha256, otssha256LTree} {
+		pub, sig, err := s.SignSealAndDestroy(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.Verify(pub, msg, sig) {
+			t.Fatalf("signature verification failed")
+		}
+	}
+	faulty := NewScheme(sha256.New, rand.Reader, WithChainHasher(faultyChainHasher{NewHashChainHasher(sha256.New)}))
+	pub, sig, err := faulty.SignSealAndDestroy(msg)
+	if err != ErrSelfVerification {
+		t.Fatalf("expected ErrSelfVerification, got %v", err)
+	}
+	if pub != nil || sig != nil {
+		t.Fatalf("returned public key or signature on fault")
+	}
+}
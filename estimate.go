@@ -0,0 +1,107 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// ErrLowSecurity is returned by ValidateSecurity for usable schemes with
+// a limited security margin.
+var ErrLowSecurity = errors.New("wots: message hash output is shorter than 256 bits")
+
+// SeedSize returns the size in bytes of a seed from which private keys
+// can be derived. It's equal to the message hash function output size.
+func (s *Scheme) SeedSize() int { return s.digestSize }
+
+// StorageEstimate returns the total number of bytes needed to store n
+// private keys, n public keys, and n signatures of the given scheme.
+func StorageEstimate(s *Scheme, n int) (privBytes, pubBytes, sigBytes int64) {
+	return int64(n) * int64(s.PrivateKeySize()),
+		int64(n) * int64(s.PublicKeySize()),
+		int64(n) * int64(s.SignatureSize())
+}
+
+// SeedStorageEstimate returns the total number of bytes needed to store
+// seeds for n private keys of the given scheme instead of the keys.
+func SeedStorageEstimate(s *Scheme, n int) int64 {
+	return int64(n) * int64(s.SeedSize())
+}
+
+// SecurityBits returns a rough estimate of the classical security level of
+// the scheme in bits. It's informational and is not a substitute for
+// a proper security analysis.
+//
+// The estimate is the minimum of two bounds. Forging a signature by
+// inverting chains requires finding a preimage of the chain hash for one of
+// NumChains*2^w chain positions, which gives 8*blockSize bits reduced by
+// log2(NumChains*2^w), following the WOTS security reduction. Forging by
+// attacking the message hash requires a second preimage for the randomized
+// message digest, which gives 8*digestSize bits, or only a collision if
+// randomized hashing is disabled, which gives 4*digestSize bits.
+func (s *Scheme) SecurityBits() int {
+	positions := uint(s.numChains * s.chainLen)
+	chain := 8*s.blockSize - bits.Len(positions-1)
+	msg := 8 * s.digestSize
+	if s.randLen == 0 {
+		msg /= 2
+	}
+	if msg < chain {
+		return msg
+	}
+	return chain
+}
+
+// ValidateSecurity checks the scheme's hash function output sizes. It
+// returns an error if they are not supported, so that keys can't be
+// generated, or ErrLowSecurity if the scheme is usable, but its message
+// hash output is shorter than 32 bytes, which leaves a limited security
+// margin. Applications can check it when loading configuration and treat
+// ErrLowSecurity as a warning.
+func (s *Scheme) ValidateSecurity() error {
+	if !s.validHashSizes() {
+		return errors.New("wots: wrong hash output size")
+	}
+	if s.digestSize < 32 {
+		return ErrLowSecurity
+	}
+	return nil
+}
+
+// maxInt is the maximum value of int.
+const maxInt = int(^uint(0) >> 1)
+
+// ProvisioningRate returns the number of concurrent key generators, which
+// a one-time key service needs to sign msgsPerSec messages per second,
+// given the measured duration of generating one key pair in nanoseconds,
+// for example, from a benchmark. Each signature consumes one key, so the
+// keygen rate must match the message rate; with 25% headroom, the result
+// is ceil(msgsPerSec * 1.25 * benchNsPerKeygen / 1e9), saturating at the
+// maximum int. It returns 0 if either argument is not positive. It's
+// informational.
+func (s *Scheme) ProvisioningRate(msgsPerSec int, benchNsPerKeygen int64) int {
+	if msgsPerSec <= 0 || benchNsPerKeygen <= 0 {
+		return 0
+	}
+	// Nanoseconds of key generation needed per second of messages with
+	// headroom, msgsPerSec * 5/4 * benchNsPerKeygen, divided by 1e9 ns.
+	// The 128-bit product is multiplied by 5 separately, since the quotient
+	// doesn't fit 64 bits if hi*5 would reach den.
+	const den = 4 * 1e9
+	hi, lo := bits.Mul64(uint64(msgsPerSec), uint64(benchNsPerKeygen))
+	if hi >= den/5 {
+		return maxInt
+	}
+	carry, lo := bits.Mul64(lo, 5)
+	q, rem := bits.Div64(hi*5+carry, lo, den)
+	if rem != 0 {
+		q++
+	}
+	if q > uint64(maxInt) {
+		return maxInt
+	}
+	return int(q)
+}
@@ -0,0 +1,93 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+)
+
+func TestStorageEstimate(t *testing.T) {
+	// SHA-256: private key is 34*32 = 1088 bytes, public key is 32 bytes,
+	// signature is 32+34*32 = 1120 bytes.
+	priv, pub, sig := StorageEstimate(otssha256, 1000)
+	if priv != 1088000 {
+		t.Errorf("private keys: expected 1088000, got %d", priv)
+	}
+	if pub != 32000 {
+		t.Errorf("public keys: expected 32000, got %d", pub)
+	}
+	if sig != 1120000 {
+		t.Errorf("signatures: expected 1120000, got %d", sig)
+	}
+	if seeds := SeedStorageEstimate(otssha256, 1000); seeds != 32000 {
+		t.Errorf("seeds: expected 32000, got %d", seeds)
+	}
+	priv, pub, sig = StorageEstimate(otssha256, 0)
+	if priv != 0 || pub != 0 || sig != 0 {
+		t.Errorf("expected zero estimates for n=0")
+	}
+}
+
+func TestSecurityBits(t *testing.T) {
+	tests := []struct {
+		s    *Scheme
+		bits int
+	}{
+		// 256 - ceil(log2(34*256))
+		{otssha256, 242},
+		// 512 - ceil(log2(66*256))
+		{NewScheme(sha512.New, rand.Reader), 497},
+		// 256/2, message hash collision
+		{NewScheme(sha256.New, rand.Reader, WithoutRandomizedHashing()), 128},
+		// 256 - ceil(log2(67*16))
+		{NewScheme(sha256.New, rand.Reader, WithW(4)), 245},
+	}
+	for _, test := range tests {
+		if bits := test.s.SecurityBits(); bits != test.bits {
+			t.Errorf("%s: expected %d, got %d", test.s.ID(), test.bits, bits)
+		}
+	}
+}
+
+func TestValidateSecurity(t *testing.T) {
+	if err := otssha256.ValidateSecurity(); err != nil {
+		t.Fatalf("sha256: expected no error, got %v", err)
+	}
+	if err := NewScheme(newXOFHash(16), rand.Reader).ValidateSecurity(); err != ErrLowSecurity {
+		t.Fatalf("16-byte hash: expected %v, got %v", ErrLowSecurity, err)
+	}
+	err := NewScheme(newXOFHash(8), rand.Reader).ValidateSecurity()
+	if err == nil || err == ErrLowSecurity {
+		t.Fatalf("8-byte hash: expected fatal error, got %v", err)
+	}
+}
+
+func TestProvisioningRate(t *testing.T) {
+	tests := []struct {
+		msgsPerSec int
+		ns         int64
+		rate       int
+	}{
+		{1000, 1000000, 2},     // 1250 keys/s, 1000 keys/s per generator
+		{1000, 800000, 1},      // 1250 keys/s per generator
+		{1000, 2000000, 3},     // 500 keys/s per generator
+		{100, 3000000000, 375}, // 1/3 key/s per generator
+		{3, 1000000, 1},
+		{1000, 0, 0},
+		{maxInt, 1 << 62, maxInt},
+		{1 << 30, 1 << 30, 1441151881},
+		{maxInt, 1 << 40, maxInt},
+		{0, 1000000, 0},
+	}
+	for _, test := range tests {
+		if rate := otssha256.ProvisioningRate(test.msgsPerSec, test.ns); rate != test.rate {
+			t.Errorf("%d messages/s, %d ns/keygen: expected %d, got %d",
+				test.msgsPerSec, test.ns, test.rate, rate)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/dchest/wots"
+)
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add([]byte(""), uint16(0))
+	f.Add([]byte("hello world!"), uint16(5))
+	f.Add([]byte("0123456789abcdef0123456789abcdef"), uint16(1119))
+	f.Add(make([]byte, 100), uint16(40000))
+
+	s := wots.NewScheme(sha256.New, zeroReader{})
+	seed := []byte("fuzz seed for reproducible keys!")
+	priv, pub, err := s.DeriveKeyPair(seed, 0)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, msg []byte, pos uint16) {
+		sig, err := s.Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.Verify(pub, msg, sig) {
+			t.Fatalf("failed to verify correct signature")
+		}
+
+		badSig := append([]byte(nil), sig...)
+		badSig[int(pos)%len(badSig)] ^= 1
+		if s.Verify(pub, msg, badSig) {
+			t.Fatalf("verified signature with modified byte %d", int(pos)%len(badSig))
+		}
+
+		badPub := append(wots.PublicKey(nil), pub...)
+		badPub[int(pos)%len(badPub)] ^= 1
+		if s.Verify(badPub, msg, sig) {
+			t.Fatalf("verified signature with modified public key")
+		}
+
+		if len(msg) > 0 {
+			badMsg := append([]byte(nil), msg...)
+			badMsg[int(pos)%len(badMsg)] ^= 1
+			if s.Verify(pub, badMsg, sig) {
+				t.Fatalf("verified signature of modified message")
+			}
+		}
+		if s.Verify(pub, append(msg, 0), sig) {
+			t.Fatalf("verified signature of extended message")
+		}
+	})
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(b []byte) (int, error) {
+	for i := range b {
+		b[i] = 0
+	}
+	return len(b), nil
+}
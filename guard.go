@@ -0,0 +1,90 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrRandomizerReused is returned by RandomizerGuard when the random
+// reader produced a randomization parameter that has already been used.
+var ErrRandomizerReused = errors.New("wots: randomization parameter has been reused")
+
+// RandomizerGuard signs messages like Scheme.Sign, but remembers hashes of
+// randomization parameters it has used and refuses to sign if the random
+// reader repeats one of them, which indicates a broken reader. It
+// remembers up to a fixed number of the most recent parameters.
+//
+// If randomized hashing is disabled, RandomizerGuard doesn't check anything.
+//
+// RandomizerGuard is safe for concurrent use by multiple goroutines.
+type RandomizerGuard struct {
+	scheme *Scheme
+	mu     sync.Mutex
+	seen   map[string]struct{}
+	order  []string // ring buffer of seen hashes
+	next   int      // next position in order
+}
+
+// NewRandomizerGuard returns a new guard for the scheme, which remembers
+// up to max most recent randomization parameters. It panics if max is not
+// positive.
+func NewRandomizerGuard(s *Scheme, max int) *RandomizerGuard {
+	if max <= 0 {
+		panic("wots: randomizer guard size must be positive")
+	}
+	return &RandomizerGuard{
+		scheme: s,
+		seen:   make(map[string]struct{}, max),
+		order:  make([]string, 0, max),
+	}
+}
+
+// add records the randomization parameter r. It returns
+// ErrRandomizerReused if r has already been recorded.
+func (g *RandomizerGuard) add(r []byte) error {
+	h := g.scheme.hashFunc()
+	h.Write(r)
+	tag := string(h.Sum(nil))
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.seen[tag]; ok {
+		return ErrRandomizerReused
+	}
+	if len(g.order) < cap(g.order) {
+		g.order = append(g.order, tag)
+	} else {
+		delete(g.seen, g.order[g.next])
+		g.order[g.next] = tag
+		g.next = (g.next + 1) % len(g.order)
+	}
+	g.seen[tag] = struct{}{}
+	return nil
+}
+
+// Sign signs message using the given private key. It returns
+// ErrRandomizerReused without signing if the randomization parameter read
+// from the scheme's random reader has already been used.
+//
+// IMPORTANT: Do not use the same private key to sign more than one message!
+// It's a one-time signature.
+func (g *RandomizerGuard) Sign(privateKey PrivateKey, message []byte) ([]byte, error) {
+	s := g.scheme
+	if err := s.checkPrivateKey(privateKey); err != nil {
+		return nil, err
+	}
+	sg := s.newSigner()
+	if _, err := io.ReadFull(s.rand, sg.r); err != nil {
+		return nil, err
+	}
+	if s.randLen > 0 {
+		if err := g.add(sg.r); err != nil {
+			return nil, err
+		}
+	}
+	return sg.signWithRandomizer(make([]byte, 0, s.SignatureSize()), privateKey, sg.r, message), nil
+}
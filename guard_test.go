@@ -0,0 +1,57 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestRandomizerGuard(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// zeroReader returns the same randomization parameter every time.
+	g := NewRandomizerGuard(NewScheme(sha256.New, zeroReader), 2)
+	msg := []byte(testMessage)
+	sig, err := g.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !otssha256.Verify(pub, msg, sig) {
+		t.Fatalf("signature verification failed")
+	}
+	if _, err := g.Sign(priv, msg); err != ErrRandomizerReused {
+		t.Fatalf("expected ErrRandomizerReused, got %v", err)
+	}
+}
+
+func TestRandomizerGuardBounded(t *testing.T) {
+	priv, _, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewRandomizerGuard(otssha256, 3)
+	for i := 0; i < 10; i++ {
+		if _, err := g.Sign(priv, []byte(testMessage)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(g.seen) != 3 || len(g.order) != 3 {
+		t.Fatalf("expected 3 remembered parameters, got %d", len(g.seen))
+	}
+	// The oldest parameter is forgotten.
+	if err := g.add(make([]byte, otssha256.RandomizerLen())); err != nil {
+		t.Fatal(err)
+	}
+	first := g.order[g.next]
+	if err := g.add([]byte("another")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.seen[first]; ok {
+		t.Fatalf("oldest parameter is not forgotten")
+	}
+}
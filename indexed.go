@@ -0,0 +1,43 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "encoding/binary"
+
+// indexedMessage returns message prefixed with 8-byte big-endian index.
+func indexedMessage(index uint64, message []byte) []byte {
+	m := make([]byte, 8, 8+len(message))
+	binary.BigEndian.PutUint64(m, index)
+	return append(m, message...)
+}
+
+// SignIndexedDeterministic signs message using the given private key,
+// binding index into the message digest. The randomization parameter
+// is derived from the private key and index with HMAC, as keys are
+// derived in DeriveKeyPair, instead of being read from the scheme's
+// random reader, so signing the same message with
+// the same key and index always produces the same signature.
+//
+// The signature must be verified with VerifyIndexed.
+//
+// IMPORTANT: Do not use the same private key to sign more than one message!
+// It's a one-time signature. Signing the same message again with the same
+// index is harmless, since it produces the same signature.
+func (s *Scheme) SignIndexedDeterministic(privateKey PrivateKey, index uint64, message []byte) ([]byte, error) {
+	if err := s.checkPrivateKey(privateKey); err != nil {
+		return nil, err
+	}
+	r := make([]byte, s.randLen)
+	s.expandSeed(r, privateKey, "wots randomizer", index)
+	sig := make([]byte, 0, s.SignatureSize())
+	return s.newSigner().signWithRandomizer(sig, privateKey, r, indexedMessage(index, message)), nil
+}
+
+// VerifyIndexed verifies the signature of message made by
+// SignIndexedDeterministic with the given index using the public key,
+// and returns true iff the signature is valid.
+func (s *Scheme) VerifyIndexed(publicKey PublicKey, index uint64, message []byte, sig []byte) bool {
+	return s.Verify(publicKey, indexedMessage(index, message), sig)
+}
@@ -0,0 +1,70 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestSignIndexedDeterministic(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig1, err := otssha256.SignIndexedDeterministic(priv, 42, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := otssha256.SignIndexedDeterministic(priv, 42, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig1, sig2) {
+		t.Fatalf("signatures with the same index differ")
+	}
+	if !otssha256.VerifyIndexed(pub, 42, msg, sig1) {
+		t.Fatalf("failed to verify correct signature")
+	}
+	if otssha256.VerifyIndexed(pub, 43, msg, sig1) {
+		t.Fatalf("verified signature with wrong index")
+	}
+	if otssha256.Verify(pub, msg, sig1) {
+		t.Fatalf("verified indexed signature without index")
+	}
+	sig3, err := otssha256.SignIndexedDeterministic(priv, 43, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(sig1[:otssha256.SeedSize()], sig3[:otssha256.SeedSize()]) {
+		t.Fatalf("randomizers for different indexes are equal")
+	}
+	if _, err := otssha256.SignIndexedDeterministic(priv[1:], 42, msg); err == nil {
+		t.Fatalf("signed with wrong private key size")
+	}
+}
+
+func TestSignIndexedDeterministicLongRandomizer(t *testing.T) {
+	s := NewScheme(sha256.New, zeroReader, WithRandomizerLen(64))
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := s.SignIndexedDeterministic(priv, 42, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.VerifyIndexed(pub, 42, msg, sig) {
+		t.Fatalf("failed to verify correct signature")
+	}
+	// Both halves of the randomizer must be derived, not zero-padded.
+	r := sig[:64]
+	if bytes.Equal(r[32:], make([]byte, 32)) || bytes.Equal(r[:32], r[32:]) {
+		t.Fatalf("randomizer is not fully derived: %x", r)
+	}
+}
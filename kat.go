@@ -0,0 +1,105 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"hash"
+)
+
+// katVector is a known-answer test vector.
+type katVector struct {
+	hash    func() hash.Hash
+	opts    []Option
+	pub     string // hex-encoded public key
+	sigHash string // hex-encoded SHA-256 hash of signature
+}
+
+var katVectors = []katVector{
+	{
+		hash:    sha256.New,
+		pub:     "7f4f1294871de5c4a530d0a0a9ac1da7203eb5aa4d0f1dcc66a83960ae9ed0d8",
+		sigHash: "1095a8ca5ec4764d43bdca4df72753a31b85f491b42f36ca653560020c71b639",
+	},
+	{
+		hash: sha512.New,
+		pub: "8400534c6f3040f02592f56cb5539aab63a73a3b2b13a562eaf83d4006fff798" +
+			"d3f73e9a9ae0748698cc4555098dbe94e15f89cc47966f7023789222d843acd0",
+		sigHash: "3fd7eb5ea14f60176a3095fb35c222366f077fdc49eb513f490bf1a64bfecaae",
+	},
+	{
+		hash:    sha256.New,
+		opts:    []Option{WithW(4)},
+		pub:     "31edf2153fbdd1d0146c016bd650bb047e174fee4b6c5fa211c7fcb2846ed12e",
+		sigHash: "41672185c752f8ba119bd00a367e44ddb99bddb5a85d59e4ca8facfa30fca1c0",
+	},
+	{
+		hash: sha256.New,
+		opts: []Option{WithLTree()},
+		pub: "f79b6ced37042cddcbb7d3bebc689f2e2b71fc73c668b2d5171c2d9827a0d44d" +
+			"fa552d962ddaf2fe7dc630e22c310efa4af070733dcf08ab0ced46f7f6f8e39f",
+		sigHash: "1095a8ca5ec4764d43bdca4df72753a31b85f491b42f36ca653560020c71b639",
+	},
+	{
+		hash:    sha256.New,
+		opts:    []Option{WithRandHash(RandHashPrefix)},
+		pub:     "7f4f1294871de5c4a530d0a0a9ac1da7203eb5aa4d0f1dcc66a83960ae9ed0d8",
+		sigHash: "84f69ecf4b4a5d8f6ef94c30505148ced724c959b496c7be8139851fee544000",
+	},
+	{
+		hash:    sha256.New,
+		opts:    []Option{WithChecksumFirst()},
+		pub:     "7f4f1294871de5c4a530d0a0a9ac1da7203eb5aa4d0f1dcc66a83960ae9ed0d8",
+		sigHash: "3b2f1e1a6e22c137aed00345b2e5c969944a24193a7e50b35dc352cbc45b8d39",
+	},
+}
+
+// RunKAT runs known-answer tests of key derivation, signing with a fixed
+// randomization parameter, and verification for SHA-256 and SHA-512
+// schemes in different modes and with both randomized hashing
+// constructions, and checks that tampered signatures are rejected.
+// It returns an error if any test fails.
+//
+// It doesn't use randomness and is fast enough to be called at startup
+// of applications which require self-tests.
+func RunKAT() error {
+	return runKAT(katVectors)
+}
+
+func runKAT(vectors []katVector) error {
+	seed := sha512.Sum512([]byte("wots known-answer test seed"))
+	message := []byte("wots known-answer test message")
+	for _, v := range vectors {
+		s := NewScheme(v.hash, nil, v.opts...)
+		r := bytes.Repeat([]byte{0x5a}, s.RandomizerLen())
+		_, pub, sig, err := GenerateInteropVector(s, seed[:], message, r)
+		if err != nil {
+			return err
+		}
+		sigHash := sha256.Sum256(sig)
+		if hex.EncodeToString(pub) != v.pub {
+			return errors.New("wots: known-answer test failed: public key mismatch")
+		}
+		if hex.EncodeToString(sigHash[:]) != v.sigHash {
+			return errors.New("wots: known-answer test failed: signature mismatch")
+		}
+		if !s.Verify(pub, message, sig) {
+			return errors.New("wots: known-answer test failed: valid signature rejected")
+		}
+		sig[len(sig)-1] ^= 1
+		if s.Verify(pub, message, sig) {
+			return errors.New("wots: known-answer test failed: tampered signature accepted")
+		}
+		sig[len(sig)-1] ^= 1
+		if s.Verify(pub, message[1:], sig) {
+			return errors.New("wots: known-answer test failed: signature of wrong message accepted")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "testing"
+
+func TestRunKAT(t *testing.T) {
+	if err := RunKAT(); err != nil {
+		t.Fatal(err)
+	}
+	for i := range katVectors {
+		for _, field := range []string{"pub", "sigHash"} {
+			vectors := append([]katVector(nil), katVectors...)
+			v := &vectors[i]
+			p := &v.pub
+			if field == "sigHash" {
+				p = &v.sigHash
+			}
+			b := []byte(*p)
+			if b[0] == '0' {
+				b[0] = '1'
+			} else {
+				b[0] = '0'
+			}
+			*p = string(b)
+			if err := runKAT(vectors); err == nil {
+				t.Errorf("vector %d: no error for corrupted %s", i, field)
+			}
+		}
+	}
+}
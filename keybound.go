@@ -0,0 +1,36 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "errors"
+
+// keyBoundMessage returns message prefixed with the public key.
+func keyBoundMessage(publicKey PublicKey, message []byte) []byte {
+	m := make([]byte, 0, len(publicKey)+len(message))
+	m = append(m, publicKey...)
+	return append(m, message...)
+}
+
+// SignKeyBound is like Sign, but binds the public key into the message
+// digest, so that the signature is valid only under this public key,
+// which prevents key substitution attacks. The public key must correspond
+// to the private key, otherwise the signature will not verify.
+//
+// The signature must be verified with VerifyKeyBound.
+//
+// IMPORTANT: Do not use the same private key to sign more than one message!
+// It's a one-time signature.
+func (s *Scheme) SignKeyBound(privateKey PrivateKey, publicKey PublicKey, message []byte) ([]byte, error) {
+	if len(publicKey) != s.PublicKeySize() {
+		return nil, errors.New("wots: public key size doesn't match the scheme")
+	}
+	return s.Sign(privateKey, keyBoundMessage(publicKey, message))
+}
+
+// VerifyKeyBound verifies the signature of message made by SignKeyBound
+// using the public key, and returns true iff the signature is valid.
+func (s *Scheme) VerifyKeyBound(publicKey PublicKey, message []byte, sig []byte) bool {
+	return s.Verify(publicKey, keyBoundMessage(publicKey, message), sig)
+}
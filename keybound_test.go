@@ -0,0 +1,39 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "testing"
+
+func TestSignKeyBound(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.SignKeyBound(priv, pub, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !otssha256.VerifyKeyBound(pub, msg, sig) {
+		t.Fatalf("failed to verify correct signature")
+	}
+	if otssha256.VerifyKeyBound(pub, msg[1:], sig) {
+		t.Fatalf("verified wrong message")
+	}
+	if otssha256.Verify(pub, msg, sig) {
+		t.Fatalf("verified key-bound signature without key binding")
+	}
+
+	_, otherPub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otssha256.VerifyKeyBound(otherPub, msg, sig) {
+		t.Fatalf("verified signature under another key")
+	}
+	if _, err := otssha256.SignKeyBound(priv, pub[1:], msg); err == nil {
+		t.Fatalf("no error for wrong public key size")
+	}
+}
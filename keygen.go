@@ -0,0 +1,149 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"errors"
+	"hash"
+	"io"
+)
+
+// KeygenContext generates key pairs reusing the private key buffer, the
+// public key buffer, and hash function instances between calls to
+// Generate, which avoids allocations in key provisioning loops.
+//
+// KeygenContext is not safe for concurrent use.
+type KeygenContext struct {
+	scheme     *Scheme
+	blockHash  hash.Hash
+	keyHash    *keyHasher
+	endpoints  []byte
+	privateKey PrivateKey
+	publicKey  PublicKey
+}
+
+// NewKeygenContext returns a new key generation context for the scheme.
+func (s *Scheme) NewKeygenContext() *KeygenContext {
+	return &KeygenContext{
+		scheme:     s,
+		blockHash:  s.chainFunc(),
+		keyHash:    s.newKeyHasher(nil),
+		endpoints:  s.newEndpoints(),
+		privateKey: make([]byte, s.PrivateKeySize()),
+		publicKey:  make([]byte, 0, s.PublicKeySize()),
+	}
+}
+
+// Generate reads a new private key from the scheme's random reader into
+// the context's buffer and returns the corresponding public key. The
+// private key is available with PrivateKey.
+//
+// The returned public key and the private key are overwritten by the
+// next call to Generate, so the caller must copy them to keep them.
+func (kc *KeygenContext) Generate() (PublicKey, error) {
+	s := kc.scheme
+	if !s.validHashSizes() {
+		return nil, errors.New("wots: wrong hash output size")
+	}
+	if _, err := io.ReadFull(s.rand, kc.privateKey); err != nil {
+		return nil, err
+	}
+	kc.publicKey = s.appendPublicKey(kc.publicKey[:0], kc.privateKey, kc.blockHash, kc.keyHash, kc.endpoints)
+	return kc.publicKey, nil
+}
+
+// PrivateKey returns the private key generated by the last call to
+// Generate. It's a reference to the context's buffer.
+func (kc *KeygenContext) PrivateKey() PrivateKey {
+	return kc.privateKey
+}
+
+// WipePrivate wipes the private key buffer.
+func (kc *KeygenContext) WipePrivate() {
+	for i := range kc.privateKey {
+		kc.privateKey[i] = 0
+	}
+}
+
+// KeygenProgress creates the public key from a private key incrementally,
+// a few chains at a time, which allows splitting key generation for
+// schemes with large parameters into time slices on constrained devices.
+// The progress can be saved with Endpoints and restored with ResumeKeygen.
+//
+// KeygenProgress is not safe for concurrent use.
+type KeygenProgress struct {
+	scheme     *Scheme
+	blockHash  hash.Hash
+	privateKey PrivateKey
+	endpoints  []byte // endpoints of processed chains
+}
+
+// NewKeygenProgress returns a new incremental public key creation from the
+// private key, which must not be modified until the public key is created.
+func (s *Scheme) NewKeygenProgress(privateKey PrivateKey) *KeygenProgress {
+	return &KeygenProgress{
+		scheme:     s,
+		blockHash:  s.chainFunc(),
+		privateKey: privateKey,
+		endpoints:  s.newEndpoints(),
+	}
+}
+
+// ResumeKeygen is like NewKeygenProgress, but continues public key creation
+// with chain endpoints saved from the previous progress with Endpoints.
+func (s *Scheme) ResumeKeygen(privateKey PrivateKey, endpoints []byte) (*KeygenProgress, error) {
+	if len(endpoints)%s.blockSize != 0 || len(endpoints) > s.numChains*s.blockSize {
+		return nil, errors.New("wots: wrong chain endpoints size")
+	}
+	p := s.NewKeygenProgress(privateKey)
+	p.endpoints = append(p.endpoints, endpoints...)
+	return p, nil
+}
+
+// done reports whether all chains have been processed.
+func (p *KeygenProgress) done() bool {
+	return len(p.endpoints) == cap(p.endpoints)
+}
+
+// Step processes up to n more chains and reports whether all chains have
+// been processed. It processes nothing if the private key size is wrong,
+// in which case PublicKey returns an error.
+func (p *KeygenProgress) Step(n int) bool {
+	s := p.scheme
+	if len(p.privateKey) != s.PrivateKeySize() {
+		return true
+	}
+	for ; n > 0 && !p.done(); n-- {
+		i := len(p.endpoints) / s.blockSize
+		start := s.startBlock(p.privateKey[i*s.blockSize:(i+1)*s.blockSize], i)
+		p.endpoints = s.appendChain(p.endpoints, p.blockHash, start, i, 0, s.chainLen)
+	}
+	return p.done()
+}
+
+// Endpoints returns chain endpoints calculated so far, from which the
+// progress can be restored with ResumeKeygen.
+func (p *KeygenProgress) Endpoints() []byte {
+	return append([]byte(nil), p.endpoints...)
+}
+
+// PublicKey returns the public key. It returns an error if not all chains
+// have been processed, or the private key size is wrong.
+func (p *KeygenProgress) PublicKey() (PublicKey, error) {
+	s := p.scheme
+	if len(p.privateKey) != s.PrivateKeySize() {
+		return nil, errors.New("wots: private key size doesn't match the scheme")
+	}
+	if !p.done() {
+		return nil, errors.New("wots: public key creation is not complete")
+	}
+	var salt []byte
+	if s.ltree {
+		salt = s.publicKeySalt(p.privateKey)
+	}
+	keyHash := s.newKeyHasher(salt)
+	keyHash.addEndpoints(p.endpoints, s.blockSize)
+	return keyHash.appendSum(salt), nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestKeygenContext(t *testing.T) {
+	for _, s := range []*Scheme{otssha256, otssha256LTree} {
+		kc := s.NewKeygenContext()
+		for i := 0; i < 3; i++ {
+			pub, err := kc.Generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := s.PublicKeyFromPrivate(kc.PrivateKey())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(pub, expected) {
+				t.Fatalf("expected %x, got %x", expected, pub)
+			}
+		}
+		kc.WipePrivate()
+		for _, v := range kc.PrivateKey() {
+			if v != 0 {
+				t.Fatalf("private key is not wiped")
+			}
+		}
+	}
+}
+
+func BenchmarkGenerateKeyPair(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := otssha256Insecure.GenerateKeyPair(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkKeygenContext(b *testing.B) {
+	kc := otssha256Insecure.NewKeygenContext()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := kc.Generate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestKeygenProgress(t *testing.T) {
+	for _, s := range []*Scheme{otssha256, otssha256LTree} {
+		priv, pub, err := s.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := s.NewKeygenProgress(priv)
+		steps := 0
+		for !p.Step(1) {
+			steps++
+			if _, err := p.PublicKey(); err == nil {
+				t.Fatalf("no error for incomplete public key")
+			}
+			if steps == s.NumChains()/2 {
+				// Save and restore progress.
+				p, err = s.ResumeKeygen(priv, p.Endpoints())
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		if steps != s.NumChains()-1 {
+			t.Fatalf("expected %d steps, got %d", s.NumChains()-1, steps)
+		}
+		result, err := p.PublicKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(result, pub) {
+			t.Fatalf("expected %x, got %x", pub, result)
+		}
+	}
+	if _, err := otssha256.ResumeKeygen(nil, make([]byte, 5)); err == nil {
+		t.Fatalf("no error for wrong endpoints size")
+	}
+	p := otssha256.NewKeygenProgress(make([]byte, 5))
+	if !p.Step(1) {
+		t.Fatalf("not done for wrong private key size")
+	}
+	if _, err := p.PublicKey(); err == nil {
+		t.Fatalf("no error for wrong private key size")
+	}
+}
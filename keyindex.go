@@ -0,0 +1,89 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// FingerprintSize is the size of public key fingerprint in bytes.
+const FingerprintSize = 16
+
+// ErrUnknownFingerprint is returned by KeyIndexVerifier when there's
+// no public key with the given fingerprint.
+var ErrUnknownFingerprint = errors.New("wots: unknown public key fingerprint")
+
+// Fingerprint returns a short identifier of the public key, which is the
+// first FingerprintSize bytes of its Commitment. Fingerprints are suitable
+// for referencing keys in storage, but not as commitments: they are too
+// short to resist collision attacks.
+func (s *Scheme) Fingerprint(publicKey PublicKey) []byte {
+	return s.Commitment(publicKey)[:FingerprintSize]
+}
+
+// keyIDSize is the number of bytes of the public key hash in KeyID.
+const keyIDSize = 20
+
+// KeyID returns an identifier of the public key, which is the first 20
+// bytes of its SHA-256 hash encoded in lowercase base32 without padding,
+// 32 characters long. It depends only on the key bytes, so it's the same
+// regardless of the scheme and the format the key was stored in, and it's
+// safe for use in URLs and file names.
+//
+// Unlike Fingerprint, KeyID doesn't depend on the scheme's hash function.
+func (k PublicKey) KeyID() string {
+	sum := sha256.Sum256(k)
+	return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(sum[:keyIDSize]))
+}
+
+// KeyIndexVerifier verifies signatures by public keys referenced by their
+// fingerprints.
+//
+// KeyIndexVerifier is safe for concurrent use by multiple goroutines.
+type KeyIndexVerifier struct {
+	scheme *Scheme
+	mu     sync.RWMutex
+	keys   map[string]PublicKey // fingerprint -> public key
+}
+
+// NewVerifierWithKeyIndex returns a new empty verifier with key index.
+func (s *Scheme) NewVerifierWithKeyIndex() *KeyIndexVerifier {
+	return &KeyIndexVerifier{
+		scheme: s,
+		keys:   make(map[string]PublicKey),
+	}
+}
+
+// Add adds the public key to the index and returns its fingerprint.
+func (v *KeyIndexVerifier) Add(publicKey PublicKey) []byte {
+	fp := v.scheme.Fingerprint(publicKey)
+	v.mu.Lock()
+	v.keys[string(fp)] = append(PublicKey(nil), publicKey...)
+	v.mu.Unlock()
+	return fp
+}
+
+// Lookup returns the public key with the given fingerprint from the index.
+func (v *KeyIndexVerifier) Lookup(fingerprint []byte) (PublicKey, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	publicKey, ok := v.keys[string(fingerprint)]
+	return publicKey, ok
+}
+
+// VerifyByFingerprint verifies the signature of message using the public
+// key with the given fingerprint, and returns true iff the signature is
+// valid. It returns ErrUnknownFingerprint if the index doesn't have the key.
+func (v *KeyIndexVerifier) VerifyByFingerprint(fingerprint, message, sig []byte) (bool, error) {
+	publicKey, ok := v.Lookup(fingerprint)
+	if !ok {
+		return false, ErrUnknownFingerprint
+	}
+	return v.scheme.Verify(publicKey, message, sig), nil
+}
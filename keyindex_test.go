@@ -0,0 +1,107 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestKeyIndexVerifier(t *testing.T) {
+	v := otssha256.NewVerifierWithKeyIndex()
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp := v.Add(pub)
+	if len(fp) != FingerprintSize {
+		t.Fatalf("expected fingerprint size %d, got %d", FingerprintSize, len(fp))
+	}
+	if !bytes.Equal(fp, otssha256.Fingerprint(pub)) {
+		t.Fatalf("fingerprints differ")
+	}
+	if found, ok := v.Lookup(fp); !ok || !bytes.Equal(found, pub) {
+		t.Fatalf("public key not found by fingerprint")
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := v.VerifyByFingerprint(fp, msg, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("signature verification failed")
+	}
+	ok, err = v.VerifyByFingerprint(fp, []byte("wrong message"), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf("verified signature for wrong message")
+	}
+	if _, err := v.VerifyByFingerprint(otssha256.Fingerprint(otherPub), msg, sig); err != ErrUnknownFingerprint {
+		t.Fatalf("expected ErrUnknownFingerprint, got %v", err)
+	}
+}
+
+func TestKeyID(t *testing.T) {
+	_, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := pub.KeyID()
+	if len(id) != 32 || strings.ToLower(id) != id {
+		t.Fatalf("expected 32 lowercase characters, got %q", id)
+	}
+
+	text, err := pub.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromText PublicKey
+	if err := fromText.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	fromJSON := struct{ Key PublicKey }{}
+	data, err := json.Marshal(struct{ Key PublicKey }{pub})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	fromArmor, err := UnarmorPublicKey(ArmorPublicKey(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromBytes := PublicKey(append([]byte(nil), pub...))
+	for name, k := range map[string]PublicKey{
+		"text":   fromText,
+		"JSON":   fromJSON.Key,
+		"armor":  fromArmor,
+		"binary": fromBytes,
+	} {
+		if k.KeyID() != id {
+			t.Errorf("%s: expected key ID %q, got %q", name, id, k.KeyID())
+		}
+	}
+
+	_, pub2, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub2.KeyID() == id {
+		t.Fatalf("different keys have the same key ID")
+	}
+}
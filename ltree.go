@@ -0,0 +1,124 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"crypto/hmac"
+	"encoding/binary"
+	"hash"
+)
+
+// WithLTree returns an option, which makes the scheme compute public keys
+// as a root of a salted L-tree (binary hash tree, similar to XMSS) over the
+// chain endpoints instead of a hash of the concatenated endpoints.
+//
+// The salt is derived from the private key and stored at the beginning of
+// the public key, which makes public keys two times longer. Public keys of
+// this mode are incompatible with the default mode.
+func WithLTree() Option {
+	return func(s *Scheme) { s.ltree = true }
+}
+
+// publicKeySalt returns the L-tree salt derived from the private key.
+func (s *Scheme) publicKeySalt(privateKey PrivateKey) []byte {
+	mac := hmac.New(s.hashFunc, privateKey)
+	mac.Write([]byte("wots public key salt"))
+	return mac.Sum(nil)[:s.digestSize]
+}
+
+// splitPublicKey returns the L-tree salt (nil in the default mode) and the
+// key hash of the public key, which must have a correct size.
+func (s *Scheme) splitPublicKey(publicKey PublicKey) (salt, key []byte) {
+	if s.ltree {
+		return publicKey[:s.digestSize], publicKey[s.digestSize:]
+	}
+	return nil, publicKey
+}
+
+// keyHasher folds chain endpoints into a public key hash.
+type keyHasher struct {
+	h     hash.Hash
+	salt  []byte   // L-tree salt, nil in the default mode
+	tag   []byte   // scheme parameters tag, nil if not bound
+	nodes [][]byte // L-tree leaves
+}
+
+// newKeyHasher returns a new key hasher. If salt is nil, the public key
+// hash is the hash of endpoints, otherwise it's the salted L-tree root.
+func (s *Scheme) newKeyHasher(salt []byte) *keyHasher {
+	k := &keyHasher{h: s.hashFunc(), tag: s.paramsTag}
+	k.reset(salt)
+	return k
+}
+
+// reset resets the key hasher to hash a new public key with the given salt.
+func (k *keyHasher) reset(salt []byte) {
+	k.h.Reset()
+	k.salt = salt
+	k.nodes = k.nodes[:0]
+	if salt == nil {
+		k.h.Write(k.tag)
+	}
+}
+
+// node returns the hash of L-tree node at the given level and index.
+func (k *keyHasher) node(level, index int, left, right []byte) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint32(b[:4], uint32(level))
+	binary.BigEndian.PutUint32(b[4:], uint32(index))
+	k.h.Reset()
+	k.h.Write(k.salt)
+	k.h.Write(k.tag)
+	k.h.Write(b[:])
+	k.h.Write(left)
+	k.h.Write(right)
+	return k.h.Sum(nil)
+}
+
+// add adds the next chain endpoint.
+func (k *keyHasher) add(endpoint []byte) {
+	if k.salt == nil {
+		k.h.Write(endpoint)
+		return
+	}
+	k.nodes = append(k.nodes, k.node(0, len(k.nodes), endpoint, nil))
+}
+
+// addEndpoints adds contiguous chain endpoints of the given size.
+func (k *keyHasher) addEndpoints(endpoints []byte, size int) {
+	if k.salt == nil {
+		k.h.Write(endpoints)
+		return
+	}
+	for ; len(endpoints) > 0; endpoints = endpoints[size:] {
+		k.add(endpoints[:size])
+	}
+}
+
+// sum returns the public key hash.
+func (k *keyHasher) sum() []byte {
+	return k.appendSum(nil)
+}
+
+// appendSum appends the public key hash to dst and returns the result.
+func (k *keyHasher) appendSum(dst []byte) []byte {
+	if k.salt == nil {
+		return appendHashSum(dst, k.h)
+	}
+	// Hash pairs of nodes on each level, promoting the odd
+	// node to the next level, until the root is left.
+	nodes := k.nodes
+	for level := 1; len(nodes) > 1; level++ {
+		next := nodes[:0]
+		for i := 0; i+1 < len(nodes); i += 2 {
+			next = append(next, k.node(level, i/2, nodes[i], nodes[i+1]))
+		}
+		if len(nodes)%2 == 1 {
+			next = append(next, nodes[len(nodes)-1])
+		}
+		nodes = next
+	}
+	return append(dst, nodes[0]...)
+}
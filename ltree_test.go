@@ -0,0 +1,98 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+var otssha256LTree = NewScheme(sha256.New, rand.Reader, WithLTree())
+
+func TestLTree(t *testing.T) {
+	s := otssha256LTree
+	if s.PublicKeySize() != 64 {
+		t.Fatalf("public key size: expected 64, got %d", s.PublicKeySize())
+	}
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Verify(pub, msg, sig) {
+		t.Fatalf("failed to verify correct signature")
+	}
+	if !s.VerifyConstantTime(pub, msg, sig) {
+		t.Fatalf("failed to verify correct signature in constant time")
+	}
+	if s.Verify(pub, msg[1:], sig) {
+		t.Fatalf("verified wrong message")
+	}
+	badPub := append(PublicKey(nil), pub...)
+	badPub[0] ^= 1 // salt
+	if s.Verify(badPub, msg, sig) {
+		t.Fatalf("verified with wrong salt")
+	}
+	if otssha256.Verify(pub[s.digestSize:], msg, sig) {
+		t.Fatalf("verified L-tree signature in default mode")
+	}
+	var text PublicKey
+	b, _ := pub.MarshalText()
+	if err := text.UnmarshalText(b); err != nil {
+		t.Fatalf("unmarshaling public key: %s", err)
+	}
+}
+
+func TestLTreeSalt(t *testing.T) {
+	priv, _, err := otssha256LTree.DeriveKeyPair(testSeed, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	salt := otssha256LTree.publicKeySalt(priv)
+	otherSalt := append([]byte(nil), salt...)
+	otherSalt[0] ^= 1
+	root := func(salt []byte) []byte {
+		k := otssha256LTree.newKeyHasher(salt)
+		blockHash := sha256.New()
+		for i := 0; i < len(priv); i += 32 {
+			k.add(hashBlock(blockHash, priv[i:i+32], 256))
+		}
+		return k.sum()
+	}
+	if bytes.Equal(root(salt), root(otherSalt)) {
+		t.Fatalf("roots with different salts are equal")
+	}
+}
+
+func TestLTreeKAT(t *testing.T) {
+	katPub := "a14a0204b3e5bacc73d162a689f195f0574682739256f3258057cd2696c92230" +
+		"b7461d6c9ca85cb83f2af22f5797d7fdbeb307ba2260c02cd35774fe41b701d0"
+	katSigHash := "703235cfc710ec5b2b6c869c7dcf9f1f8bb77982d1c1454c0b28f03966d9267d"
+	s := NewScheme(sha256.New, zeroReader, WithLTree())
+	priv, pub, err := s.DeriveKeyPair(testSeed, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(pub) != katPub {
+		t.Fatalf("public key: expected %s, got %x", katPub, pub)
+	}
+	sig, err := s.Sign(priv, []byte(testMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := sha256.Sum256(sig); hex.EncodeToString(h[:]) != katSigHash {
+		t.Fatalf("signature hash: expected %s, got %x", katSigHash, h)
+	}
+	if !s.Verify(pub, []byte(testMessage), sig) {
+		t.Fatalf("failed to verify signature")
+	}
+}
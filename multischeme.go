@@ -0,0 +1,49 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"errors"
+	"strconv"
+)
+
+// MultiSchemeVerifier verifies signatures made with any of several schemes,
+// selected by scheme ID, for example, to accept signatures made with
+// a deprecated scheme during migration to a new one.
+//
+// MultiSchemeVerifier is safe for concurrent use by multiple goroutines.
+type MultiSchemeVerifier struct {
+	schemes map[string]*Scheme // scheme ID -> scheme
+}
+
+// NewMultiSchemeVerifier returns a new verifier for the given schemes. It
+// returns an error if any scheme has no ID, which is the case if its hash
+// functions are not registered with RegisterHash, or if schemes have the
+// same ID.
+func NewMultiSchemeVerifier(schemes ...*Scheme) (*MultiSchemeVerifier, error) {
+	v := &MultiSchemeVerifier{schemes: make(map[string]*Scheme, len(schemes))}
+	for _, s := range schemes {
+		id := s.ID()
+		if id == "" {
+			return nil, errors.New("wots: scheme has no ID")
+		}
+		if _, ok := v.schemes[id]; ok {
+			return nil, errors.New("wots: duplicate scheme ID " + strconv.Quote(id))
+		}
+		v.schemes[id] = s
+	}
+	return v, nil
+}
+
+// Verify verifies the signature of message using the public key with the
+// scheme with the given ID, and returns true iff the signature is valid.
+// It returns an error if the verifier has no scheme with this ID.
+func (v *MultiSchemeVerifier) Verify(schemeID string, publicKey PublicKey, message, sig []byte) (bool, error) {
+	s, ok := v.schemes[schemeID]
+	if !ok {
+		return false, errors.New("wots: unknown scheme ID " + strconv.Quote(schemeID))
+	}
+	return s.Verify(publicKey, message, sig), nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"testing"
+)
+
+func TestMultiSchemeVerifier(t *testing.T) {
+	sha512Scheme := NewScheme(sha512.New, rand.Reader)
+	v, err := NewMultiSchemeVerifier(otssha256, sha512Scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	for _, s := range []*Scheme{otssha256, sha512Scheme} {
+		priv, pub, err := s.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := s.Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := v.Verify(s.ID(), pub, msg, sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("%s: failed to verify correct signature", s.ID())
+		}
+		if ok, _ := v.Verify(s.ID(), pub, msg[1:], sig); ok {
+			t.Fatalf("%s: verified wrong message", s.ID())
+		}
+	}
+	if _, err := v.Verify("wots-sha384", nil, msg, nil); err == nil {
+		t.Fatalf("no error for unknown scheme ID")
+	}
+	if _, err := NewMultiSchemeVerifier(otssha256, otssha256Insecure); err == nil {
+		t.Fatalf("no error for duplicate scheme ID")
+	}
+	if _, err := NewMultiSchemeVerifier(NewScheme(newXOFHash(32), rand.Reader)); err == nil {
+		t.Fatalf("no error for scheme without ID")
+	}
+}
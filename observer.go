@@ -0,0 +1,56 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+// VerifyReason describes why signature verification failed.
+type VerifyReason int
+
+const (
+	// VerifyBadPublicKeySize means that the public key size doesn't
+	// match the scheme.
+	VerifyBadPublicKeySize VerifyReason = iota + 1
+
+	// VerifyBadSignatureSize means that the signature size doesn't
+	// match the scheme.
+	VerifyBadSignatureSize
+
+	// VerifyMismatch means that the public key recovered from the
+	// signature doesn't match the given public key.
+	VerifyMismatch
+
+	// VerifyNilPublicKey means that the public key is nil or empty.
+	VerifyNilPublicKey
+)
+
+func (r VerifyReason) String() string {
+	switch r {
+	case VerifyBadPublicKeySize:
+		return "bad public key size"
+	case VerifyBadSignatureSize:
+		return "bad signature size"
+	case VerifyMismatch:
+		return "mismatch"
+	case VerifyNilPublicKey:
+		return "nil public key"
+	}
+	return "unknown"
+}
+
+// SetVerifyObserver sets the function, which Verify and other methods
+// verifying a signature with a public key call with the reason of each
+// verification failure, for example, to collect metrics. It's not called
+// for valid signatures. Passing nil removes the observer.
+//
+// SetVerifyObserver must not be called concurrently with using the scheme.
+func (s *Scheme) SetVerifyObserver(observer func(reason VerifyReason)) {
+	s.verifyObserver = observer
+}
+
+// observeVerify reports verification failure to the observer, if any.
+func (s *Scheme) observeVerify(reason VerifyReason) {
+	if s.verifyObserver != nil {
+		s.verifyObserver(reason)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestVerifyObserver(t *testing.T) {
+	s := NewScheme(sha256.New, rand.Reader)
+	var reasons []VerifyReason
+	s.SetVerifyObserver(func(reason VerifyReason) {
+		reasons = append(reasons, reason)
+	})
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		pub    PublicKey
+		msg    []byte
+		sig    []byte
+		reason VerifyReason
+	}{
+		{nil, msg, sig, VerifyNilPublicKey},
+		{pub[1:], msg, sig, VerifyBadPublicKeySize},
+		{pub, msg, sig[1:], VerifyBadSignatureSize},
+		{pub, msg[1:], sig, VerifyMismatch},
+	}
+	state := NewVerifierState(s)
+	verifiers := map[string]func(pub PublicKey, msg, sig []byte) bool{
+		"Verify": s.Verify,
+		"VerifyWithDeadline": func(pub PublicKey, msg, sig []byte) bool {
+			ok, _ := s.VerifyWithDeadline(context.Background(), pub, msg, sig)
+			return ok
+		},
+		"VerifierState":      state.Verify,
+		"VerifyConstantTime": s.VerifyConstantTime,
+		"VerifyDigits": func(pub PublicKey, msg, sig []byte) bool {
+			digits := s.messageDigits(sig[:s.randLen], msg)
+			return s.VerifyDigits(pub, digits, sig)
+		},
+		"VerifyStream": func(pub PublicKey, msg, sig []byte) bool {
+			ok, _ := s.VerifyStream(pub, bytes.NewReader(msg), sig)
+			return ok
+		},
+		"VerifyDistance": func(pub PublicKey, msg, sig []byte) bool {
+			ok, _ := s.VerifyDistance(pub, msg, sig)
+			return ok
+		},
+		"VerifyCounted": func(pub PublicKey, msg, sig []byte) bool {
+			ok, _ := s.VerifyCounted(pub, msg, sig)
+			return ok
+		},
+		"VerifyWitness": func(pub PublicKey, msg, sig []byte) bool {
+			ok, _ := s.VerifyWitness(pub, msg, sig)
+			return ok
+		},
+	}
+	for name, verify := range verifiers {
+		for _, test := range tests {
+			reasons = nil
+			if verify(test.pub, test.msg, test.sig) {
+				t.Fatalf("%s: %s: verified invalid signature", name, test.reason)
+			}
+			if len(reasons) != 1 || reasons[0] != test.reason {
+				t.Fatalf("%s: expected reason %q, got %v", name, test.reason, reasons)
+			}
+		}
+	}
+	reasons = nil
+	if !s.Verify(pub, msg, sig) {
+		t.Fatalf("signature verification failed")
+	}
+	if len(reasons) != 0 {
+		t.Fatalf("observer called for valid signature: %v", reasons)
+	}
+	s.SetVerifyObserver(nil)
+	if s.Verify(pub, msg[1:], sig) || len(reasons) != 0 {
+		t.Fatalf("removed observer was called")
+	}
+}
+
+func TestVerifyObserverSHA256Scheme(t *testing.T) {
+	fast := NewSHA256Scheme(rand.Reader)
+	var reasons []VerifyReason
+	fast.Scheme().SetVerifyObserver(func(reason VerifyReason) {
+		reasons = append(reasons, reason)
+	})
+	priv, pub, err := fast.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := fast.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fast.Verify(nil, msg, sig) || fast.Verify(pub, msg[1:], sig) || !fast.Verify(pub, msg, sig) {
+		t.Fatalf("wrong verification results")
+	}
+	if len(reasons) != 2 || reasons[0] != VerifyNilPublicKey || reasons[1] != VerifyMismatch {
+		t.Fatalf("expected nil public key and mismatch, got %v", reasons)
+	}
+}
@@ -0,0 +1,368 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// checksumConvention describes how checksum digits are computed: by
+// summing 1<<w minus each message digit and encoding the sum in big-endian
+// base 1<<w digits.
+const checksumConvention = "sum-chainlen-minus-digit-be"
+
+// builtinHashes lists hash functions registered by default, in the order
+// of BuiltinSchemeTable entries.
+var builtinHashes = []struct {
+	id string
+	h  func() hash.Hash
+}{
+	{"sha224", sha256.New224},
+	{"sha256", sha256.New},
+	{"sha384", sha512.New384},
+	{"sha512", sha512.New},
+	{"sha512_224", sha512.New512_224},
+	{"sha512_256", sha512.New512_256},
+}
+
+var (
+	hashesMu        sync.RWMutex
+	hashes, hashIDs = builtinRegistry()
+)
+
+// builtinRegistry returns maps of built-in hash functions by id and of
+// their ids by hash probe.
+func builtinRegistry() (map[string]func() hash.Hash, map[string]string) {
+	hashes := make(map[string]func() hash.Hash, len(builtinHashes))
+	ids := make(map[string]string, len(builtinHashes))
+	for _, b := range builtinHashes {
+		hashes[b.id] = b.h
+		ids[string(hashProbe(b.h))] = b.id
+	}
+	return hashes, ids
+}
+
+// RegisterHash registers the hash function under the given id, so that it
+// can be identified in scheme parameters. Hash functions from crypto/sha256
+// and crypto/sha512 packages are registered by default as "sha224",
+// "sha256", "sha384", "sha512", "sha512_224", and "sha512_256".
+// Registering a different hash function under an existing id replaces it.
+//
+// It panics if the id is empty, contains dashes, or can be confused with
+// a scheme ID option, such as "ltree" or "w4", or if the same hash
+// function is already registered under a different id, so that scheme
+// IDs are unambiguous.
+func RegisterHash(id string, h func() hash.Hash) {
+	if id == "" || strings.Contains(id, "-") || isOptionToken(id) {
+		panic("wots: invalid hash function id " + strconv.Quote(id))
+	}
+	probe := string(hashProbe(h))
+	hashesMu.Lock()
+	defer hashesMu.Unlock()
+	if other, ok := hashIDs[probe]; ok && other != id {
+		panic("wots: hash function is already registered as " + strconv.Quote(other))
+	}
+	if old, ok := hashes[id]; ok {
+		delete(hashIDs, string(hashProbe(old)))
+	}
+	hashes[id] = h
+	hashIDs[probe] = id
+}
+
+// isOptionToken reports whether t is parsed as an option in scheme IDs.
+func isOptionToken(t string) bool {
+	switch t {
+	case "ltree", "norand", "rprefix", "lenprefix", "bind", "csfirst":
+		return true
+	}
+	if len(t) > 1 && (t[0] == 'r' || t[0] == 'w') {
+		_, err := strconv.Atoi(t[1:])
+		return err == nil
+	}
+	return false
+}
+
+// lookupHash returns the registered hash function with the given id.
+func lookupHash(id string) (func() hash.Hash, bool) {
+	hashesMu.RLock()
+	defer hashesMu.RUnlock()
+	h, ok := hashes[id]
+	return h, ok
+}
+
+// hashProbe returns the hash of a fixed input, used for identifying
+// hash functions.
+func hashProbe(h func() hash.Hash) []byte {
+	d := h()
+	d.Write([]byte("wots hash identification probe"))
+	return d.Sum(nil)
+}
+
+// hashID returns the id of the registered hash function, which produces
+// the same output as h, or an empty string if there's no such function.
+func hashID(h func() hash.Hash) string {
+	probe := string(hashProbe(h))
+	hashesMu.RLock()
+	defer hashesMu.RUnlock()
+	return hashIDs[probe]
+}
+
+// Params describes parameters of a scheme.
+type Params struct {
+	ID                 string `json:"id"`
+	Hash               string `json:"hash"`
+	ChainHash          string `json:"chainHash"`
+	DigestSize         int    `json:"digestSize"`
+	BlockSize          int    `json:"blockSize"`
+	PublicKeySize      int    `json:"publicKeySize"`
+	W                  int    `json:"w"`
+	ChecksumConvention string `json:"checksumConvention"`
+	LTree              bool   `json:"ltree,omitempty"`
+	NoRandomizer       bool   `json:"noRandomizer,omitempty"`
+	RandomizerLen      int    `json:"randomizerLen,omitempty"` // if not default
+	RandHashPrefix     bool   `json:"randHashPrefix,omitempty"`
+	LengthPrefix       bool   `json:"lengthPrefix,omitempty"`
+	ParamBinding       bool   `json:"paramBinding,omitempty"`
+	ChecksumFirst      bool   `json:"checksumFirst,omitempty"`
+}
+
+// BuiltinSchemeTable returns parameters of default schemes, as created by
+// NewScheme without options, for every hash function registered by
+// default, ordered by hash function id.
+func BuiltinSchemeTable() []Params {
+	table := make([]Params, len(builtinHashes))
+	for i, b := range builtinHashes {
+		table[i] = NewScheme(b.h, nil).Params()
+	}
+	return table
+}
+
+// Params returns parameters of the scheme. Hash function ids are empty
+// if the hash functions are not registered with RegisterHash.
+func (s *Scheme) Params() Params {
+	return Params{
+		ID:                 s.ID(),
+		Hash:               hashID(s.hashFunc),
+		ChainHash:          hashID(s.chainFunc),
+		DigestSize:         s.digestSize,
+		BlockSize:          s.blockSize,
+		PublicKeySize:      s.PublicKeySize(),
+		W:                  s.w,
+		ChecksumConvention: checksumConvention,
+		LTree:              s.ltree,
+		NoRandomizer:       s.randLen == 0,
+		RandomizerLen:      s.customRandLen(),
+		RandHashPrefix:     s.randHash == RandHashPrefix,
+		LengthPrefix:       s.lenPrefix,
+		ParamBinding:       s.bindParams,
+		ChecksumFirst:      s.csFirst,
+	}
+}
+
+// customRandLen returns the randomization parameter size if it's set with
+// WithRandomizerLen to a value other than the message hash output size,
+// otherwise 0.
+func (s *Scheme) customRandLen() int {
+	if s.randLen == s.digestSize {
+		return 0
+	}
+	return s.randLen
+}
+
+// ID returns the scheme identifier, consisting of hash function ids and
+// non-default options, for example, "wots-sha256" or "wots-sha256-sha512-w4".
+// It returns an empty string if the hash functions are not registered
+// with RegisterHash, or the scheme uses a chain start transform or
+// a chain hasher.
+func (s *Scheme) ID() string {
+	h, chain := hashID(s.hashFunc), hashID(s.chainFunc)
+	if h == "" || chain == "" || s.chainStart != nil || s.chainHasher != nil {
+		return ""
+	}
+	id := "wots-" + h
+	if chain != h {
+		id += "-" + chain
+	}
+	if s.w != 8 {
+		id += "-w" + strconv.Itoa(s.w)
+	}
+	if s.ltree {
+		id += "-ltree"
+	}
+	if s.randLen == 0 {
+		id += "-norand"
+	}
+	if n := s.customRandLen(); n != 0 {
+		id += "-r" + strconv.Itoa(n)
+	}
+	if s.randHash == RandHashPrefix {
+		id += "-rprefix"
+	}
+	if s.lenPrefix {
+		id += "-lenprefix"
+	}
+	if s.bindParams {
+		id += "-bind"
+	}
+	if s.csFirst {
+		id += "-csfirst"
+	}
+	return id
+}
+
+// Compatible reports whether signatures and keys of the scheme can be used
+// with the other scheme, that is, whether both schemes have the same
+// parameters and hash functions. Schemes without ID, such as those with
+// hash functions that are not registered with RegisterHash, are never
+// compatible, since their identity can't be established.
+func (s *Scheme) Compatible(other *Scheme) bool {
+	p := s.Params()
+	if p.ID == "" {
+		return false
+	}
+	return p == other.Params()
+}
+
+// SchemeByID returns a new scheme with the given ID, as returned by
+// Scheme.ID, using the given random byte reader. It returns an error if
+// the ID is malformed or not canonical, or its hash functions are not
+// registered.
+func SchemeByID(id string, rand io.Reader) (*Scheme, error) {
+	tokens := strings.Split(id, "-")
+	if len(tokens) < 2 || tokens[0] != "wots" {
+		return nil, errors.New("wots: malformed scheme ID " + strconv.Quote(id))
+	}
+	h, ok := lookupHash(tokens[1])
+	if !ok {
+		return nil, errors.New("wots: unknown hash function " + strconv.Quote(tokens[1]))
+	}
+	chain := h
+	tokens = tokens[2:]
+	if len(tokens) > 0 {
+		if c, ok := lookupHash(tokens[0]); ok {
+			chain = c
+			tokens = tokens[1:]
+		}
+	}
+	var opts []Option
+	for _, t := range tokens {
+		switch {
+		case t == "ltree":
+			opts = append(opts, WithLTree())
+		case t == "norand":
+			opts = append(opts, WithoutRandomizedHashing())
+		case t == "rprefix":
+			opts = append(opts, WithRandHash(RandHashPrefix))
+		case t == "lenprefix":
+			opts = append(opts, WithLengthPrefix())
+		case t == "bind":
+			opts = append(opts, WithParamBinding())
+		case t == "csfirst":
+			opts = append(opts, WithChecksumFirst())
+		case strings.HasPrefix(t, "r"):
+			n, err := strconv.Atoi(t[1:])
+			if err != nil || !isHashSize(n) {
+				return nil, errors.New("wots: unsupported randomization parameter size in scheme ID " + strconv.Quote(id))
+			}
+			opts = append(opts, WithRandomizerLen(n))
+		case strings.HasPrefix(t, "w"):
+			w, err := strconv.Atoi(t[1:])
+			if err != nil || !isSupportedW(w) {
+				return nil, errors.New("wots: unsupported parameter w in scheme ID " + strconv.Quote(id))
+			}
+			opts = append(opts, WithW(w))
+		default:
+			return nil, errors.New("wots: malformed scheme ID " + strconv.Quote(id))
+		}
+	}
+	s, err := newScheme2(h, chain, rand, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if s.ID() != id {
+		return nil, errors.New("wots: scheme ID " + strconv.Quote(id) + " is not canonical")
+	}
+	return s, nil
+}
+
+// MarshalConfig returns JSON-encoded parameters of the scheme, from which
+// the scheme can be reconstructed with UnmarshalConfig. It returns an
+// error if the hash functions are not registered with RegisterHash.
+func (s *Scheme) MarshalConfig() ([]byte, error) {
+	p := s.Params()
+	if s.chainStart != nil {
+		return nil, errors.New("wots: scheme with chain start transform can't be marshaled")
+	}
+	if s.chainHasher != nil {
+		return nil, errors.New("wots: scheme with chain hasher can't be marshaled")
+	}
+	if p.ID == "" {
+		return nil, errors.New("wots: scheme hash function is not registered")
+	}
+	return json.Marshal(p)
+}
+
+// UnmarshalConfig returns a new scheme with parameters encoded by
+// MarshalConfig, using the given random byte reader. It returns an error
+// if the hash functions are not registered, or the parameters are
+// inconsistent or unsupported.
+func UnmarshalConfig(data []byte, rand io.Reader) (*Scheme, error) {
+	var p Params
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	h, ok := lookupHash(p.Hash)
+	if !ok {
+		return nil, errors.New("wots: unknown hash function " + strconv.Quote(p.Hash))
+	}
+	chain, ok := lookupHash(p.ChainHash)
+	if !ok {
+		return nil, errors.New("wots: unknown hash function " + strconv.Quote(p.ChainHash))
+	}
+	if !isSupportedW(p.W) {
+		return nil, errors.New("wots: unsupported parameter w")
+	}
+	opts := []Option{WithW(p.W)}
+	if p.LTree {
+		opts = append(opts, WithLTree())
+	}
+	if p.NoRandomizer {
+		opts = append(opts, WithoutRandomizedHashing())
+	}
+	if p.RandomizerLen != 0 {
+		if !isHashSize(p.RandomizerLen) {
+			return nil, errors.New("wots: unsupported randomization parameter size")
+		}
+		opts = append(opts, WithRandomizerLen(p.RandomizerLen))
+	}
+	if p.RandHashPrefix {
+		opts = append(opts, WithRandHash(RandHashPrefix))
+	}
+	if p.LengthPrefix {
+		opts = append(opts, WithLengthPrefix())
+	}
+	if p.ParamBinding {
+		opts = append(opts, WithParamBinding())
+	}
+	if p.ChecksumFirst {
+		opts = append(opts, WithChecksumFirst())
+	}
+	s, err := newScheme2(h, chain, rand, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if s.Params() != p {
+		return nil, errors.New("wots: scheme parameters don't match")
+	}
+	return s, nil
+}
@@ -0,0 +1,230 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"strings"
+	"testing"
+)
+
+func TestSchemeID(t *testing.T) {
+	tests := []struct {
+		s  *Scheme
+		id string
+	}{
+		{otssha256, "wots-sha256"},
+		{NewScheme(sha512.New, rand.Reader), "wots-sha512"},
+		{NewScheme2(sha256.New, sha512.New, rand.Reader, WithW(4)), "wots-sha256-sha512-w4"},
+		{otssha256LTree, "wots-sha256-ltree"},
+		{NewScheme(sha256.New, rand.Reader, WithoutRandomizedHashing()), "wots-sha256-norand"},
+		{NewScheme(sha256.New, rand.Reader, WithRandHash(RandHashPrefix)), "wots-sha256-rprefix"},
+		{NewScheme(newXOFHash(32), rand.Reader), ""},
+	}
+	for _, test := range tests {
+		if id := test.s.ID(); id != test.id {
+			t.Errorf("expected %q, got %q", test.id, id)
+		}
+	}
+}
+
+func TestCompatible(t *testing.T) {
+	s := NewScheme2(sha256.New, sha512.New, rand.Reader, WithW(4))
+	if !s.Compatible(NewScheme2(sha256.New, sha512.New, nil, WithW(4))) {
+		t.Errorf("matching schemes are not compatible")
+	}
+	for _, other := range []*Scheme{
+		NewScheme2(sha256.New, sha512.New, rand.Reader),
+		NewScheme2(sha512.New, sha256.New, rand.Reader, WithW(4)),
+		NewScheme2(sha256.New, sha512.New512_256, rand.Reader, WithW(4)),
+		NewScheme2(sha256.New, sha512.New, rand.Reader, WithW(4), WithLTree()),
+		NewScheme2(sha256.New, sha512.New, rand.Reader, WithW(4), WithoutRandomizedHashing()),
+	} {
+		if s.Compatible(other) || other.Compatible(s) {
+			t.Errorf("%s is compatible with %s", s.ID(), other.ID())
+		}
+	}
+	xof := NewScheme(newXOFHash(32), rand.Reader)
+	if xof.Compatible(xof) {
+		t.Errorf("scheme with unregistered hash is compatible")
+	}
+}
+
+func TestSchemeByID(t *testing.T) {
+	for _, orig := range []*Scheme{
+		otssha256,
+		otssha256LTree,
+		NewScheme(sha512.New, rand.Reader),
+		NewScheme2(sha256.New, sha512.New, rand.Reader, WithW(4), WithLTree()),
+		NewScheme(sha256.New, rand.Reader, WithoutRandomizedHashing(), WithRandHash(RandHashPrefix)),
+	} {
+		s, err := SchemeByID(orig.ID(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Params() != orig.Params() {
+			t.Fatalf("expected %+v, got %+v", orig.Params(), s.Params())
+		}
+	}
+	for _, bad := range []string{
+		"",
+		"wots",
+		"ots-sha256",
+		"wots-unknown",
+		"wots-sha256-sha256",
+		"wots-sha256-w8",
+		"wots-sha256-w3",
+		"wots-sha256-wx",
+		"wots-sha256-norand-ltree",
+		"wots-sha256-unknown",
+	} {
+		if _, err := SchemeByID(bad, rand.Reader); err == nil {
+			t.Errorf("no error for %q", bad)
+		}
+	}
+}
+
+func TestMarshalConfig(t *testing.T) {
+	orig := NewScheme2(sha256.New, sha512.New, rand.Reader, WithW(4), WithLTree())
+	config, err := orig.MarshalConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := UnmarshalConfig(config, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Params() != orig.Params() {
+		t.Fatalf("expected %+v, got %+v", orig.Params(), s.Params())
+	}
+	priv, pub, err := orig.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := orig.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Verify(pub, msg, sig) {
+		t.Fatalf("reconstructed scheme failed to verify signature")
+	}
+}
+
+func TestUnmarshalConfigErrors(t *testing.T) {
+	config, err := otssha256.MarshalConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []string{
+		strings.Replace(string(config), `"hash":"sha256"`, `"hash":"unknown"`, 1),
+		strings.Replace(string(config), `"w":8`, `"w":3`, 1),
+		strings.Replace(string(config), `"digestSize":32`, `"digestSize":64`, 1),
+		strings.Replace(string(config), `"checksumConvention":"`, `"checksumConvention":"x`, 1),
+		"{",
+	} {
+		if bad == string(config) {
+			t.Fatalf("failed to modify config")
+		}
+		if _, err := UnmarshalConfig([]byte(bad), rand.Reader); err == nil {
+			t.Errorf("no error for %s", bad)
+		}
+	}
+	if _, err := NewScheme(newXOFHash(32), rand.Reader).MarshalConfig(); err == nil {
+		t.Errorf("no error for unregistered hash")
+	}
+}
+
+func TestRegisterHash(t *testing.T) {
+	RegisterHash("test_xof32", newXOFHash(32))
+	defer unregisterHash("test_xof32")
+	s := NewScheme(newXOFHash(32), rand.Reader)
+	if id := s.ID(); id != "wots-test_xof32" {
+		t.Fatalf("expected wots-test_xof32, got %q", id)
+	}
+	config, err := s.MarshalConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UnmarshalConfig(config, rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// unregisterHash removes the hash function registered with RegisterHash.
+func unregisterHash(id string) {
+	hashesMu.Lock()
+	defer hashesMu.Unlock()
+	delete(hashIDs, string(hashProbe(hashes[id])))
+	delete(hashes, id)
+}
+
+func TestRegisterHashInvalid(t *testing.T) {
+	mustPanic := func(id string, h func() hash.Hash) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%q: no panic", id)
+				unregisterHash(id)
+			}
+		}()
+		RegisterHash(id, h)
+	}
+	for _, id := range []string{"", "test-xof", "ltree", "norand", "rprefix", "lenprefix", "bind", "csfirst", "w4", "r32"} {
+		mustPanic(id, newXOFHash(48))
+	}
+	// Same hash function under a different id.
+	mustPanic("test_sha256", sha256.New)
+
+	// Allowed ids resembling option tokens.
+	RegisterHash("whirl", newXOFHash(48))
+	unregisterHash("whirl")
+
+	// Re-registering the same id replaces the hash function.
+	RegisterHash("test_xof", newXOFHash(48))
+	RegisterHash("test_xof", newXOFHash(40))
+	defer unregisterHash("test_xof")
+	if id := NewScheme(newXOFHash(48), nil).ID(); id != "" {
+		t.Fatalf("replaced hash function still has id %q", id)
+	}
+	if id := NewScheme(newXOFHash(40), nil).ID(); id != "wots-test_xof" {
+		t.Fatalf("expected wots-test_xof, got %q", id)
+	}
+}
+
+func TestBuiltinSchemeTable(t *testing.T) {
+	table := BuiltinSchemeTable()
+	if len(table) != 6 {
+		t.Fatalf("expected 6 entries, got %d", len(table))
+	}
+	found := 0
+	for _, p := range table {
+		var size int
+		switch p.ID {
+		case "wots-sha256":
+			size = 32
+		case "wots-sha512":
+			size = 64
+		default:
+			continue
+		}
+		found++
+		if p.DigestSize != size || p.BlockSize != size || p.PublicKeySize != size || p.W != 8 {
+			t.Fatalf("%s: wrong sizes: %+v", p.ID, p)
+		}
+		s, err := SchemeByID(p.ID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Params() != p {
+			t.Fatalf("expected %+v, got %+v", s.Params(), p)
+		}
+	}
+	if found != 2 {
+		t.Fatalf("expected SHA-256 and SHA-512 entries, found %d", found)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// SignerPool signs messages with the scheme, reusing hash instances and
+// scratch buffers across calls, which makes it suitable for high-throughput
+// signing with many different private keys.
+//
+// SignerPool is safe for concurrent use by multiple goroutines.
+type SignerPool struct {
+	scheme *Scheme
+	pool   sync.Pool
+}
+
+// NewSignerPool returns a new signer pool for the given scheme.
+func NewSignerPool(s *Scheme) *SignerPool {
+	p := &SignerPool{scheme: s}
+	p.pool.New = func() interface{} { return s.newSigner() }
+	return p
+}
+
+// Sign signs message using the given private key and returns signature.
+// The result is the same as returned by Scheme Sign method.
+//
+// IMPORTANT: Do not use the same private key to sign more than one message!
+// It's a one-time signature.
+func (p *SignerPool) Sign(privateKey PrivateKey, message []byte) ([]byte, error) {
+	sg := p.pool.Get().(*signer)
+	defer p.pool.Put(sg)
+	return sg.sign(make([]byte, 0, p.scheme.SignatureSize()), privateKey, message)
+}
+
+// SigningCluster signs messages with multiple signer pools (shards),
+// dispatching calls to them round-robin, which reduces contention on the
+// pools and, if the shards have independent random readers, on the reader.
+//
+// SigningCluster is safe for concurrent use by multiple goroutines.
+type SigningCluster struct {
+	shards []*SignerPool
+	next   uint32
+}
+
+// NewSigningCluster returns a new signing cluster for the scheme with the
+// given number of shards. If newRand is not nil, it's called for each
+// shard index to get the shard's random reader, which must be safe for
+// concurrent use, and each shard uses a copy of the scheme with that
+// reader; otherwise all shards share the scheme and its random reader.
+// It panics if the number of shards is not positive.
+func NewSigningCluster(s *Scheme, shards int, newRand func(shard int) io.Reader) *SigningCluster {
+	if shards <= 0 {
+		panic("wots: number of shards must be positive")
+	}
+	c := &SigningCluster{shards: make([]*SignerPool, shards)}
+	for i := range c.shards {
+		shard := s
+		if newRand != nil {
+			shard = s.WithRand(newRand(i))
+		}
+		c.shards[i] = NewSignerPool(shard)
+	}
+	return c
+}
+
+// Sign signs message using the given private key and returns signature.
+// The result is the same as returned by Scheme Sign method.
+//
+// IMPORTANT: Do not use the same private key to sign more than one message!
+// It's a one-time signature.
+func (c *SigningCluster) Sign(privateKey PrivateKey, message []byte) ([]byte, error) {
+	i := atomic.AddUint32(&c.next, 1) % uint32(len(c.shards))
+	return c.shards[i].Sign(privateKey, message)
+}
@@ -0,0 +1,148 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSignerPool(t *testing.T) {
+	p := NewSignerPool(otssha256)
+	msg := []byte(testMessage)
+	for i := 0; i < 3; i++ {
+		priv, pub, err := otssha256.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := p.Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sig) != otssha256.SignatureSize() {
+			t.Fatalf("bad signature size: %d", len(sig))
+		}
+		if !otssha256.Verify(pub, msg, sig) {
+			t.Fatalf("%d: failed to verify correct signature", i)
+		}
+	}
+	if _, err := p.Sign(make(PrivateKey, 1), msg); err == nil {
+		t.Fatalf("signed with wrong private key size")
+	}
+}
+
+func TestSignerPoolMatchesSign(t *testing.T) {
+	priv, _, err := otssha256Insecure.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig1, err := otssha256Insecure.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := NewSignerPool(otssha256Insecure).Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig1, sig2) {
+		t.Fatalf("signatures differ")
+	}
+}
+
+func BenchmarkSignerPool(b *testing.B) {
+	p := NewSignerPool(otssha256Insecure)
+	msg := []byte(testMessage)
+	priv, _, err := otssha256Insecure.GenerateKeyPair()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Sign(priv, msg)
+	}
+}
+
+func TestSigningCluster(t *testing.T) {
+	c := NewSigningCluster(otssha256, 3, nil)
+	msg := []byte(testMessage)
+	for i := 0; i < 5; i++ {
+		priv, pub, err := otssha256.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := c.Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !otssha256.Verify(pub, msg, sig) {
+			t.Fatalf("%d: failed to verify correct signature", i)
+		}
+	}
+	// Shards with independent readers.
+	c = NewSigningCluster(otssha256, 2, func(shard int) io.Reader {
+		return &lockedReader{r: NewDeterministicReader([]byte{byte(shard)})}
+	})
+	priv, _, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig1, err := c.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := c.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(sig1[:otssha256.RandomizerLen()], sig2[:otssha256.RandomizerLen()]) {
+		t.Fatalf("shards used the same reader")
+	}
+}
+
+// lockedReader makes a reader safe for concurrent use.
+type lockedReader struct {
+	mu sync.Mutex
+	r  io.Reader
+}
+
+func (lr *lockedReader) Read(p []byte) (int, error) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	return lr.r.Read(p)
+}
+
+func benchmarkParallelSign(b *testing.B, sign func(PrivateKey, []byte) ([]byte, error)) {
+	msg := []byte(testMessage)
+	priv, _, err := otssha256Insecure.GenerateKeyPair()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetParallelism(4)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			sign(priv, msg)
+		}
+	})
+}
+
+func BenchmarkSignerPoolParallel(b *testing.B) {
+	s := NewScheme(otssha256.hashFunc, &lockedReader{r: NewDeterministicReader(nil)})
+	benchmarkParallelSign(b, NewSignerPool(s).Sign)
+}
+
+func BenchmarkSigningClusterParallel(b *testing.B) {
+	c := NewSigningCluster(otssha256, runtime.GOMAXPROCS(0), func(shard int) io.Reader {
+		return &lockedReader{r: NewDeterministicReader([]byte(strconv.Itoa(shard)))}
+	})
+	benchmarkParallelSign(b, c.Sign)
+}
@@ -0,0 +1,35 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+// popContext is prepended to challenges signed as proofs of possession, so
+// that a proof is never a valid signature of the challenge as a message.
+const popContext = "wots proof of possession\x00"
+
+// popMessage returns the message signed by a proof of possession.
+func popMessage(challenge []byte) []byte {
+	m := make([]byte, 0, len(popContext)+len(challenge))
+	m = append(m, popContext...)
+	return append(m, challenge...)
+}
+
+// ProofOfPossession returns a proof that the caller holds the private key,
+// which is a signature of the challenge, chosen by the verifier, such as a
+// key directory, in the proof of possession context. Verify it with
+// VerifyProofOfPossession.
+//
+// IMPORTANT: Making a proof SPENDS THE ONE-TIME PRIVATE KEY: it can't be
+// used to sign anything else afterwards. Use it only for keys, whose sole
+// purpose is to be registered, for example, as leaves of a many-time
+// signature scheme or revocation keys.
+func (s *Scheme) ProofOfPossession(privateKey PrivateKey, challenge []byte) ([]byte, error) {
+	return s.Sign(privateKey, popMessage(challenge))
+}
+
+// VerifyProofOfPossession reports whether proof, made by ProofOfPossession
+// for the challenge, is valid for the public key.
+func (s *Scheme) VerifyProofOfPossession(publicKey PublicKey, challenge, proof []byte) bool {
+	return s.Verify(publicKey, popMessage(challenge), proof)
+}
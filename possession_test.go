@@ -0,0 +1,35 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "testing"
+
+func TestProofOfPossession(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge := []byte("key directory challenge 1")
+	proof, err := otssha256.ProofOfPossession(priv, challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !otssha256.VerifyProofOfPossession(pub, challenge, proof) {
+		t.Fatalf("failed to verify correct proof")
+	}
+	if otssha256.VerifyProofOfPossession(pub, []byte("key directory challenge 2"), proof) {
+		t.Fatalf("verified proof for wrong challenge")
+	}
+	if otssha256.Verify(pub, challenge, proof) {
+		t.Fatalf("proof verified as a signature of the challenge")
+	}
+	_, otherPub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otssha256.VerifyProofOfPossession(otherPub, challenge, proof) {
+		t.Fatalf("verified proof with wrong public key")
+	}
+}
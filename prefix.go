@@ -0,0 +1,107 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"encoding"
+	"errors"
+	"io"
+)
+
+// DigestState is a saved state of randomized hashing of a message prefix,
+// created by PrefixState and used by SignWithPrefix.
+//
+// Randomized hashing XORs every message block with the randomization
+// parameter r before hashing, so the hash state of a prefix can only be
+// reused for signatures with the same r. DigestState contains r, which is
+// generated once by PrefixState, and every signature made with the state
+// includes it.
+//
+// WARNING: After the first signature made with the state is published, r
+// is no longer secret, so randomized hashing doesn't protect signatures of
+// suffixes chosen after that: their security depends on collision
+// resistance of the hash function, as if randomized hashing was disabled.
+// Use a state only for suffixes which are not chosen by untrusted parties,
+// or create a new state for each batch of signatures.
+type DigestState struct {
+	scheme *Scheme
+	r      []byte // randomization parameter
+	state  []byte // marshaled hash state
+	tmp    []byte // current block
+	n      int    // number of bytes in tmp
+	plain  bool   // see randomizedHash
+}
+
+// PrefixState generates a randomization parameter and returns the state of
+// randomized hashing of prefix, which can be used to sign multiple messages
+// that start with prefix with SignWithPrefix without rehashing it.
+//
+// The scheme's message hash function must implement
+// encoding.BinaryMarshaler and encoding.BinaryUnmarshaler, as the standard
+// library hash functions do.
+func (s *Scheme) PrefixState(prefix []byte) (*DigestState, error) {
+	if s.lenPrefix {
+		return nil, errors.New("wots: prefix state is not supported with length prefix")
+	}
+	r := make([]byte, s.randLen)
+	if _, err := io.ReadFull(s.rand, r); err != nil {
+		return nil, err
+	}
+	var rh randomizedHash
+	rh.init(s.hashFunc(), s.randHash, r, make([]byte, s.randLen))
+	rh.Write(prefix)
+	m, ok := rh.h.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, errors.New("wots: hash function state can't be saved")
+	}
+	state, err := m.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &DigestState{
+		scheme: s,
+		r:      r,
+		state:  state,
+		tmp:    rh.tmp,
+		n:      rh.n,
+		plain:  rh.plain,
+	}, nil
+}
+
+// SignWithPrefix signs the message consisting of the prefix, from which
+// the state was created, followed by suffix, using the given private key.
+// The signature is the same as the one made by Sign of prefix||suffix with
+// the randomization parameter of the state, and is verified by Verify.
+// The state is not changed, so it can be used for multiple signatures.
+//
+// IMPORTANT: Do not use the same private key to sign more than one message!
+// It's a one-time signature.
+func (s *Scheme) SignWithPrefix(privateKey PrivateKey, state *DigestState, suffix []byte) ([]byte, error) {
+	if err := s.checkPrivateKey(privateKey); err != nil {
+		return nil, err
+	}
+	if state.scheme != s {
+		return nil, errors.New("wots: digest state is from a different scheme")
+	}
+	h := s.hashFunc()
+	u, ok := h.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return nil, errors.New("wots: hash function state can't be restored")
+	}
+	if err := u.UnmarshalBinary(state.state); err != nil {
+		return nil, err
+	}
+	rh := randomizedHash{
+		h:     h,
+		r:     state.r,
+		tmp:   append([]byte(nil), state.tmp...),
+		n:     state.n,
+		plain: state.plain,
+	}
+	rh.Write(suffix)
+	sg := s.newSigner()
+	sig := make([]byte, 0, s.SignatureSize())
+	return sg.signDigest(sig, privateKey, state.r, rh.appendDigest(sg.digest[:0])), nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestSignWithPrefix(t *testing.T) {
+	for _, s := range []*Scheme{
+		otssha256,
+		NewScheme(sha256.New, rand.Reader, WithoutRandomizedHashing()),
+	} {
+		// Prefix is not a multiple of the block size.
+		prefix := bytes.Repeat([]byte("template "), 100)
+		state, err := s.PrefixState(prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, suffix := range []string{"", "first", "second suffix longer than the hash block size"} {
+			priv, pub, err := s.GenerateKeyPair()
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg := append(append([]byte(nil), prefix...), suffix...)
+			sig, err := s.SignWithPrefix(priv, state, []byte(suffix))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := s.newSigner().signWithRandomizer(nil, priv, state.r, msg)
+			if !bytes.Equal(sig, want) {
+				t.Fatalf("%q: signature differs from signature of full message", suffix)
+			}
+			if !s.Verify(pub, msg, sig) {
+				t.Fatalf("%q: signature verification failed", suffix)
+			}
+		}
+	}
+	state, err := otssha256.PrefixState([]byte("prefix"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, _, err := otssha256LTree.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := otssha256LTree.SignWithPrefix(priv, state, nil); err == nil {
+		t.Fatalf("no error for state from a different scheme")
+	}
+}
+
+func TestPrefixStateUnsupportedHash(t *testing.T) {
+	if _, err := NewScheme(newXOFHash(32), rand.Reader).PrefixState(nil); err == nil {
+		t.Fatalf("no error for hash without marshalable state")
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"errors"
+	"hash"
+)
+
+// SignPrehashed signs the digest of a message, computed by the caller
+// with the scheme's message hash function, using the given private key.
+// The digest is signed as a message, so randomized hashing is applied to it.
+//
+// Note that randomized hashing can't protect the prehashing step: the
+// security of prehashed signatures depends on collision resistance of
+// the hash function used to compute the digest.
+//
+// IMPORTANT: Do not use the same private key to sign more than one message!
+// It's a one-time signature.
+func (s *Scheme) SignPrehashed(privateKey PrivateKey, digest []byte) ([]byte, error) {
+	if len(digest) != s.digestSize {
+		return nil, errors.New("wots: digest size doesn't match the scheme")
+	}
+	return s.Sign(privateKey, digest)
+}
+
+// SignHash is like SignPrehashed, but takes the digest from h, into which
+// the caller has already written the message. It doesn't change the state
+// of h. The output size of h must be equal to the scheme's message hash
+// output size.
+//
+// IMPORTANT: Do not use the same private key to sign more than one message!
+// It's a one-time signature.
+func (s *Scheme) SignHash(privateKey PrivateKey, h hash.Hash) ([]byte, error) {
+	return s.SignPrehashed(privateKey, h.Sum(nil))
+}
+
+// VerifyPrehashed verifies the signature made by SignPrehashed or SignHash
+// of the message digest using the public key, and returns true iff the
+// signature is valid.
+func (s *Scheme) VerifyPrehashed(publicKey PublicKey, digest []byte, sig []byte) bool {
+	if len(digest) != s.digestSize {
+		s.observeVerify(VerifyMismatch)
+		return false
+	}
+	return s.Verify(publicKey, digest, sig)
+}
+
+// VerifyTranscript verifies the signature made by SignHash of the digest
+// of transcript, into which the caller has written the transcript data,
+// using the public key, and returns true iff the signature is valid. It
+// doesn't change the state of transcript. The output size of transcript
+// must be equal to the scheme's message hash output size.
+func (s *Scheme) VerifyTranscript(publicKey PublicKey, transcript hash.Hash, sig []byte) bool {
+	if transcript.Size() != s.digestSize {
+		s.observeVerify(VerifyMismatch)
+		return false
+	}
+	return s.VerifyPrehashed(publicKey, transcript.Sum(nil), sig)
+}
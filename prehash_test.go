@@ -0,0 +1,77 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+)
+
+func TestSignHash(t *testing.T) {
+	msg := []byte(testMessage)
+
+	// Sign the message directly.
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !otssha256.Verify(pub, msg, sig) {
+		t.Fatalf("failed to verify message signature")
+	}
+
+	// Sign the same data prehashed.
+	priv, pub, err = otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := sha256.New()
+	h.Write(msg)
+	sig, err = otssha256.SignHash(priv, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256(msg)
+	if !otssha256.VerifyPrehashed(pub, digest[:], sig) {
+		t.Fatalf("failed to verify prehashed signature")
+	}
+	if otssha256.Verify(pub, msg, sig) {
+		t.Fatalf("verified prehashed signature as message signature")
+	}
+	if otssha256.VerifyPrehashed(pub, digest[1:], sig) {
+		t.Fatalf("verified digest of wrong size")
+	}
+	if _, err := otssha256.SignHash(priv, sha512.New()); err == nil {
+		t.Fatalf("no error for wrong hash size")
+	}
+}
+
+func TestVerifyTranscript(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	transcript := sha256.New()
+	transcript.Write([]byte("client hello"))
+	transcript.Write([]byte("server hello"))
+	sig, err := otssha256.SignHash(priv, transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !otssha256.VerifyTranscript(pub, transcript, sig) {
+		t.Fatalf("transcript signature verification failed")
+	}
+	transcript.Write([]byte("finished"))
+	if otssha256.VerifyTranscript(pub, transcript, sig) {
+		t.Fatalf("verified signature for different transcript")
+	}
+	if otssha256.VerifyTranscript(pub, sha512.New(), sig) {
+		t.Fatalf("verified signature for transcript of wrong size")
+	}
+}
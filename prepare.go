@@ -0,0 +1,64 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"io"
+	"sync"
+)
+
+// SignHandle is a prepared signing operation created by PrepareSign.
+//
+// SignHandle is safe for concurrent use by multiple goroutines.
+type SignHandle struct {
+	mu     sync.Mutex
+	scheme *Scheme
+	key    PrivateKey // nil after use
+	r      []byte
+}
+
+// PrepareSign generates the randomization parameter for signing a message
+// with the private key and returns the commitment to it, which is its hash
+// with the scheme's message hash function, and the handle to complete
+// signing once the message is known. It allows committing to the
+// randomization parameter before seeing the message: the verifier can
+// check that the parameter in the signature, at RandomizerOffset, has the
+// committed hash.
+//
+// The handle keeps a copy of the private key until Complete is called.
+func (s *Scheme) PrepareSign(privateKey PrivateKey) (commitment []byte, handle *SignHandle, err error) {
+	if err := s.checkPrivateKey(privateKey); err != nil {
+		return nil, nil, err
+	}
+	r := make([]byte, s.randLen)
+	if _, err := io.ReadFull(s.rand, r); err != nil {
+		return nil, nil, err
+	}
+	h := s.hashFunc()
+	h.Write(r)
+	handle = &SignHandle{
+		scheme: s,
+		key:    append(PrivateKey(nil), privateKey...),
+		r:      r,
+	}
+	return h.Sum(nil), handle, nil
+}
+
+// Complete signs message with the prepared randomization parameter and
+// wipes the copy of the private key. Subsequent calls return ErrKeyUsed.
+func (h *SignHandle) Complete(message []byte) ([]byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.key == nil {
+		return nil, ErrKeyUsed
+	}
+	s := h.scheme
+	sig := s.newSigner().signWithRandomizer(make([]byte, 0, s.SignatureSize()), h.key, h.r, message)
+	for i := range h.key {
+		h.key[i] = 0
+	}
+	h.key = nil
+	return sig, nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrepareSign(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment, handle, err := otssha256.PrepareSign(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := handle.Complete(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !otssha256.Verify(pub, msg, sig) {
+		t.Fatalf("signature verification failed")
+	}
+	r := sig[otssha256.RandomizerOffset() : otssha256.RandomizerOffset()+otssha256.RandomizerLen()]
+	h := otssha256.hashFunc()
+	h.Write(r)
+	if !bytes.Equal(h.Sum(nil), commitment) {
+		t.Fatalf("randomization parameter doesn't match commitment")
+	}
+	if _, err := handle.Complete(msg); err != ErrKeyUsed {
+		t.Fatalf("expected ErrKeyUsed, got %v", err)
+	}
+	if _, _, err := otssha256.PrepareSign(priv[1:]); err == nil {
+		t.Fatalf("no error for wrong private key size")
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "crypto/subtle"
+
+// Receipt returns a fixed-size receipt of the signature of message made
+// with the public key, which is H(publicKey ‖ digest ‖ sig), where H is
+// the scheme's message hash function and digest is the randomized message
+// digest. It returns nil if the public key or signature size doesn't match
+// the scheme.
+//
+// A receipt allows storing a short record of a signature and checking
+// later with VerifyReceipt that the full data matches it. Note that it
+// doesn't verify the signature.
+func (s *Scheme) Receipt(publicKey PublicKey, message, sig []byte) []byte {
+	if len(publicKey) != s.PublicKeySize() || len(sig) != s.SignatureSize() {
+		return nil
+	}
+	h := s.hashFunc()
+	h.Write(publicKey)
+	h.Write(s.messageDigest(sig[:s.randLen], message))
+	h.Write(sig)
+	return h.Sum(nil)
+}
+
+// VerifyReceipt reports whether the receipt matches the public key,
+// message, and signature.
+func (s *Scheme) VerifyReceipt(receipt []byte, publicKey PublicKey, message, sig []byte) bool {
+	r := s.Receipt(publicKey, message, sig)
+	return r != nil && subtle.ConstantTimeCompare(r, receipt) == 1
+}
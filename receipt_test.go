@@ -0,0 +1,46 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "testing"
+
+func TestReceipt(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt := otssha256.Receipt(pub, msg, sig)
+	if len(receipt) != 32 {
+		t.Fatalf("expected 32-byte receipt, got %d", len(receipt))
+	}
+	if !otssha256.VerifyReceipt(receipt, pub, msg, sig) {
+		t.Fatalf("valid receipt doesn't match")
+	}
+	if otssha256.VerifyReceipt(receipt, pub, []byte("wrong message"), sig) {
+		t.Fatalf("receipt matches wrong message")
+	}
+	sig[len(sig)-1] ^= 1
+	if otssha256.VerifyReceipt(receipt, pub, msg, sig) {
+		t.Fatalf("receipt matches tampered signature")
+	}
+	sig[len(sig)-1] ^= 1
+	pub[0] ^= 1
+	if otssha256.VerifyReceipt(receipt, pub, msg, sig) {
+		t.Fatalf("receipt matches tampered public key")
+	}
+	pub[0] ^= 1
+	receipt[0] ^= 1
+	if otssha256.VerifyReceipt(receipt, pub, msg, sig) {
+		t.Fatalf("tampered receipt matches")
+	}
+	if otssha256.Receipt(pub, msg, sig[1:]) != nil {
+		t.Fatalf("receipt for wrong signature size")
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "errors"
+
+// Rotate signs the next public key with the current private key, linking
+// the keys into a chain: a verifier who trusts the first public key can
+// follow the chain with VerifyRotation to trust every next key.
+//
+// IMPORTANT: The rotation signature spends the current private key.
+// It must not be used to sign anything else, including other public keys.
+func (s *Scheme) Rotate(currentPriv PrivateKey, nextPub PublicKey) (sig []byte, err error) {
+	if len(nextPub) != s.PublicKeySize() {
+		return nil, errors.New("wots: public key size doesn't match the scheme")
+	}
+	return s.Sign(currentPriv, nextPub)
+}
+
+// VerifyRotation verifies the rotation signature made by Rotate, and
+// returns true iff nextPub was signed by the key corresponding to currentPub.
+func (s *Scheme) VerifyRotation(currentPub, nextPub PublicKey, sig []byte) bool {
+	if len(nextPub) != s.PublicKeySize() {
+		s.observeVerify(VerifyMismatch)
+		return false
+	}
+	return s.Verify(currentPub, nextPub, sig)
+}
@@ -0,0 +1,43 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "testing"
+
+func TestRotate(t *testing.T) {
+	var privs []PrivateKey
+	var pubs []PublicKey
+	for i := 0; i < 4; i++ {
+		priv, pub, err := otssha256.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		privs = append(privs, priv)
+		pubs = append(pubs, pub)
+	}
+	// Build a 3-link chain: each key signs the next public key.
+	var sigs [][]byte
+	for i := 0; i < 3; i++ {
+		sig, err := otssha256.Rotate(privs[i], pubs[i+1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs = append(sigs, sig)
+	}
+	for i, sig := range sigs {
+		if !otssha256.VerifyRotation(pubs[i], pubs[i+1], sig) {
+			t.Fatalf("link %d: failed to verify rotation", i)
+		}
+	}
+	if otssha256.VerifyRotation(pubs[0], pubs[2], sigs[0]) {
+		t.Fatalf("verified rotation to wrong key")
+	}
+	if otssha256.VerifyRotation(pubs[1], pubs[2], sigs[0]) {
+		t.Fatalf("verified rotation from wrong key")
+	}
+	if _, err := otssha256.Rotate(privs[3], pubs[0][1:]); err == nil {
+		t.Fatalf("no error for wrong next public key size")
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"crypto/hmac"
+	"errors"
+	"io"
+)
+
+// ErrSealedAuthentication is returned by OpenSealed if the sealed key
+// pair was tampered with or the passphrase is wrong.
+var ErrSealedAuthentication = errors.New("wots: sealed key pair authentication failed")
+
+// KDF derives a key of the given length from passphrase and salt.
+// It should be a slow password-based key derivation function, such as
+// scrypt or Argon2.
+type KDF func(passphrase, salt []byte, keyLen int) ([]byte, error)
+
+// Keypair holds a private key and the corresponding public key.
+type Keypair struct {
+	PrivateKey PrivateKey
+	PublicKey  PublicKey
+	scheme     *Scheme
+}
+
+// NewKeypair returns a key pair for the given private key.
+func (s *Scheme) NewKeypair(privateKey PrivateKey) (*Keypair, error) {
+	publicKey, err := s.PublicKeyFromPrivate(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Keypair{PrivateKey: privateKey, PublicKey: publicKey, scheme: s}, nil
+}
+
+// sealingKeys derives encryption and authentication keys from passphrase.
+func (s *Scheme) sealingKeys(kdf KDF, passphrase, salt []byte) (encKey, macKey []byte, err error) {
+	k, err := kdf(passphrase, salt, 2*s.digestSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(k) != 2*s.digestSize {
+		return nil, nil, errors.New("wots: KDF returned key of wrong size")
+	}
+	return k[:s.digestSize], k[s.digestSize:], nil
+}
+
+// xorKeyStream encrypts or decrypts data in place with the key stream
+// derived from encKey.
+func (s *Scheme) xorKeyStream(data, encKey []byte) {
+	stream := make([]byte, len(data))
+	s.expandSeed(stream, encKey, "wots sealed key", 0)
+	for i := range data {
+		data[i] ^= stream[i]
+		stream[i] = 0
+	}
+}
+
+// MarshalSealed returns the private key encrypted and authenticated with
+// a key derived from passphrase by kdf, suitable for backups. The result
+// can be opened with the scheme's OpenSealed method.
+//
+// The encryption and authentication use only the scheme's message hash
+// function: the private key is XORed with a key stream produced by HMAC
+// in counter mode, and the result is authenticated with HMAC.
+func (kp *Keypair) MarshalSealed(kdf KDF, passphrase []byte) ([]byte, error) {
+	s := kp.scheme
+	if len(kp.PrivateKey) != s.PrivateKeySize() {
+		return nil, errors.New("wots: private key size doesn't match the scheme")
+	}
+	// Format: salt ‖ encrypted private key ‖ MAC.
+	blob := make([]byte, s.digestSize, 2*s.digestSize+len(kp.PrivateKey))
+	if _, err := io.ReadFull(s.rand, blob); err != nil {
+		return nil, err
+	}
+	encKey, macKey, err := s.sealingKeys(kdf, passphrase, blob)
+	if err != nil {
+		return nil, err
+	}
+	blob = append(blob, kp.PrivateKey...)
+	s.xorKeyStream(blob[s.digestSize:], encKey)
+	mac := hmac.New(s.hashFunc, macKey)
+	mac.Write(blob)
+	return appendHashSum(blob, mac), nil
+}
+
+// OpenSealed authenticates and decrypts the key pair sealed with
+// MarshalSealed using the passphrase and kdf. It returns
+// ErrSealedAuthentication if the passphrase is wrong or blob was modified.
+func (s *Scheme) OpenSealed(kdf KDF, passphrase, blob []byte) (*Keypair, error) {
+	if len(blob) != 2*s.digestSize+s.PrivateKeySize() {
+		return nil, &ParseError{Offset: len(blob), Reason: "wrong sealed key pair size", Err: ErrWrongSize}
+	}
+	encKey, macKey, err := s.sealingKeys(kdf, passphrase, blob[:s.digestSize])
+	if err != nil {
+		return nil, err
+	}
+	n := len(blob) - s.digestSize
+	mac := hmac.New(s.hashFunc, macKey)
+	mac.Write(blob[:n])
+	if !hmac.Equal(mac.Sum(nil), blob[n:]) {
+		return nil, ErrSealedAuthentication
+	}
+	privateKey := append(PrivateKey(nil), blob[s.digestSize:n]...)
+	s.xorKeyStream(privateKey, encKey)
+	return s.NewKeypair(privateKey)
+}
+
+// AuthenticatePrivateKey returns the HMAC tag of the private key with the
+// given key, which can be stored along with the private key to detect its
+// corruption or tampering at rest with VerifyPrivateKeyMAC. Unlike
+// MarshalSealed, it doesn't encrypt the private key.
+func (s *Scheme) AuthenticatePrivateKey(privateKey PrivateKey, key []byte) []byte {
+	mac := hmac.New(s.hashFunc, key)
+	mac.Write([]byte("wots private key mac"))
+	mac.Write(privateKey)
+	return mac.Sum(nil)
+}
+
+// VerifyPrivateKeyMAC reports whether tag is the valid tag of the private
+// key returned by AuthenticatePrivateKey with the given key.
+func (s *Scheme) VerifyPrivateKeyMAC(privateKey PrivateKey, key, tag []byte) bool {
+	return hmac.Equal(s.AuthenticatePrivateKey(privateKey, key), tag)
+}
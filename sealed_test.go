@@ -0,0 +1,95 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"testing"
+)
+
+func testKDF(passphrase, salt []byte, keyLen int) ([]byte, error) {
+	return pbkdf2.Key(sha256.New, string(passphrase), salt, 1000, keyLen)
+}
+
+func TestSealedRoundTrip(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kp, err := otssha256.NewKeypair(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(kp.PublicKey, pub) {
+		t.Fatalf("wrong public key in key pair")
+	}
+	blob, err := kp.MarshalSealed(testKDF, []byte("passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(blob, priv[:otssha256.SeedSize()]) {
+		t.Fatalf("sealed key pair contains plaintext private key")
+	}
+	kp2, err := otssha256.OpenSealed(testKDF, []byte("passphrase"), blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(kp2.PrivateKey, priv) {
+		t.Fatalf("private key: expected %x, got %x", priv, kp2.PrivateKey)
+	}
+	if !bytes.Equal(kp2.PublicKey, pub) {
+		t.Fatalf("public key: expected %x, got %x", pub, kp2.PublicKey)
+	}
+}
+
+func TestSealedWrongPassphrase(t *testing.T) {
+	priv, _, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kp, err := otssha256.NewKeypair(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := kp.MarshalSealed(testKDF, []byte("passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := otssha256.OpenSealed(testKDF, []byte("wrong"), blob); err != ErrSealedAuthentication {
+		t.Fatalf("wrong passphrase: expected ErrSealedAuthentication, got %v", err)
+	}
+	blob[len(blob)/2] ^= 1
+	if _, err := otssha256.OpenSealed(testKDF, []byte("passphrase"), blob); err != ErrSealedAuthentication {
+		t.Fatalf("tampered blob: expected ErrSealedAuthentication, got %v", err)
+	}
+	if _, err := otssha256.OpenSealed(testKDF, []byte("passphrase"), blob[1:]); err == nil {
+		t.Fatalf("no error for truncated blob")
+	}
+}
+
+func TestPrivateKeyMAC(t *testing.T) {
+	priv, _, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := []byte("mac key")
+	tag := otssha256.AuthenticatePrivateKey(priv, key)
+	if !otssha256.VerifyPrivateKeyMAC(priv, key, tag) {
+		t.Fatalf("valid tag is rejected")
+	}
+	priv[10] ^= 1
+	if otssha256.VerifyPrivateKeyMAC(priv, key, tag) {
+		t.Fatalf("corrupted private key is accepted")
+	}
+	priv[10] ^= 1
+	if otssha256.VerifyPrivateKeyMAC(priv, []byte("wrong key"), tag) {
+		t.Fatalf("tag is accepted with wrong key")
+	}
+	if otssha256.VerifyPrivateKeyMAC(priv, key, tag[1:]) {
+		t.Fatalf("truncated tag is accepted")
+	}
+}
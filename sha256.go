@@ -0,0 +1,111 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+)
+
+// SHA256Scheme is a scheme specialized for SHA-256, which produces the
+// same keys and signatures as NewScheme(sha256.New, rand), but calls
+// SHA-256 directly in hash chains instead of via hash.Hash interface,
+// which makes it faster.
+type SHA256Scheme struct {
+	s *Scheme
+}
+
+// NewSHA256Scheme returns a new SHA-256 scheme with the given random byte
+// reader (must be cryptographically secure, such as crypto/rand.Reader).
+func NewSHA256Scheme(rand io.Reader) *SHA256Scheme {
+	return &SHA256Scheme{NewScheme(sha256.New, rand)}
+}
+
+// Scheme returns the equivalent generic scheme.
+func (s *SHA256Scheme) Scheme() *Scheme { return s.s }
+
+// sha256Chain hashes the block the given number of times in place.
+func sha256Chain(block *[sha256.Size]byte, times int) {
+	for i := 0; i < times; i++ {
+		*block = sha256.Sum256(block[:])
+	}
+}
+
+// GenerateKeyPair generates a new private and public key pair.
+func (s *SHA256Scheme) GenerateKeyPair() (PrivateKey, PublicKey, error) {
+	privateKey := make([]byte, s.s.PrivateKeySize())
+	if _, err := io.ReadFull(s.s.rand, privateKey); err != nil {
+		return nil, nil, err
+	}
+	publicKey, err := s.PublicKeyFromPrivate(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return privateKey, publicKey, nil
+}
+
+// PublicKeyFromPrivate returns a public key corresponding to the given private key.
+func (s *SHA256Scheme) PublicKeyFromPrivate(privateKey PrivateKey) (PublicKey, error) {
+	if err := s.s.checkPrivateKey(privateKey); err != nil {
+		return nil, err
+	}
+	keyHash := sha256.New()
+	var block [sha256.Size]byte
+	for i := 0; i < len(privateKey); i += sha256.Size {
+		copy(block[:], privateKey[i:])
+		sha256Chain(&block, 256)
+		keyHash.Write(block[:])
+	}
+	return keyHash.Sum(nil), nil
+}
+
+// Sign signs an arbitrary length message using the given private key and
+// returns signature.
+//
+// IMPORTANT: Do not use the same private key to sign more than one message!
+// It's a one-time signature.
+func (s *SHA256Scheme) Sign(privateKey PrivateKey, message []byte) ([]byte, error) {
+	if err := s.s.checkPrivateKey(privateKey); err != nil {
+		return nil, err
+	}
+	sig := make([]byte, sha256.Size, s.s.SignatureSize())
+	if _, err := io.ReadFull(s.s.rand, sig); err != nil {
+		return nil, err
+	}
+	var block [sha256.Size]byte
+	for _, v := range s.s.messageDigits(sig, message) {
+		copy(block[:], privateKey)
+		sha256Chain(&block, v)
+		sig = append(sig, block[:]...)
+		privateKey = privateKey[sha256.Size:]
+	}
+	return sig, nil
+}
+
+// Verify verifies the signature of message using the public key,
+// and returns true iff the signature is valid.
+//
+// Note: verification time depends on message and signature.
+func (s *SHA256Scheme) Verify(publicKey PublicKey, message []byte, sig []byte) bool {
+	if !s.s.checkVerifySizes(publicKey, sig) {
+		return false
+	}
+	digits := s.s.messageDigits(sig[:sha256.Size], message)
+	sig = sig[sha256.Size:]
+	keyHash := sha256.New()
+	var block [sha256.Size]byte
+	for _, v := range digits {
+		copy(block[:], sig)
+		sha256Chain(&block, 256-v)
+		keyHash.Write(block[:])
+		sig = sig[sha256.Size:]
+	}
+	if !bytes.Equal(keyHash.Sum(nil), publicKey) {
+		s.s.observeVerify(VerifyMismatch)
+		return false
+	}
+	return true
+}
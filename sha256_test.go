@@ -0,0 +1,80 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestSHA256SchemeParity(t *testing.T) {
+	fast := NewSHA256Scheme(zeroReader)
+	priv, pub, err := otssha256Insecure.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub2, err := fast.PublicKeyFromPrivate(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pub, pub2) {
+		t.Fatalf("public key: expected %x, got %x", pub, pub2)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256Insecure.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := fast.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, sig2) {
+		t.Fatalf("signatures differ")
+	}
+	if !fast.Verify(pub, msg, sig) {
+		t.Fatalf("failed to verify correct signature")
+	}
+	if fast.Verify(pub, msg[1:], sig) {
+		t.Fatalf("verified wrong message")
+	}
+	if fast.Scheme().ID() != "wots-sha256" {
+		t.Fatalf("wrong scheme id %q", fast.Scheme().ID())
+	}
+}
+
+func TestSHA256SchemeVerify(t *testing.T) {
+	fast := NewSHA256Scheme(rand.Reader)
+	priv, pub, err := fast.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := fast.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !otssha256.Verify(pub, msg, sig) {
+		t.Fatalf("generic scheme failed to verify signature")
+	}
+	if _, err := fast.Sign(priv[1:], msg); err == nil {
+		t.Fatalf("signed with wrong private key size")
+	}
+}
+
+func BenchmarkSignVerifySHA256Specialized(b *testing.B) {
+	s := NewSHA256Scheme(zeroReader)
+	msg := []byte(testMessage)
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sig, _ := s.Sign(priv, msg)
+		s.Verify(pub, msg, sig)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrKeyUsed is returned when signing with a single-use private key
+// that has already been used.
+var ErrKeyUsed = errors.New("wots: single-use private key has already been used")
+
+// SingleUsePrivateKey is a private key that enforces the one-time usage:
+// it signs only one message and then wipes itself.
+//
+// SingleUsePrivateKey is safe for concurrent use by multiple goroutines.
+type SingleUsePrivateKey struct {
+	mu     sync.Mutex
+	scheme *Scheme
+	key    PrivateKey // nil after use
+}
+
+// GenerateSingleUse generates a new single-use private key and the
+// corresponding public key.
+func (s *Scheme) GenerateSingleUse() (*SingleUsePrivateKey, PublicKey, error) {
+	privateKey, publicKey, err := s.GenerateKeyPair()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &SingleUsePrivateKey{scheme: s, key: privateKey}, publicKey, nil
+}
+
+// Sign signs message and wipes the private key. Subsequent calls return
+// ErrKeyUsed. The key is consumed even if signing fails.
+func (k *SingleUsePrivateKey) Sign(message []byte) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.key == nil {
+		return nil, ErrKeyUsed
+	}
+	sig, err := k.scheme.Sign(k.key, message)
+	for i := range k.key {
+		k.key[i] = 0
+	}
+	k.key = nil
+	return sig, err
+}
+
+// Used reports whether the private key has been used.
+func (k *SingleUsePrivateKey) Used() bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.key == nil
+}
+
+// Unsafe returns the underlying private key, or nil if it has been used.
+//
+// IMPORTANT: The returned private key is not protected from reuse. It is
+// meant only for storing the key, which must be done before signing.
+func (k *SingleUsePrivateKey) Unsafe() PrivateKey {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.key
+}
@@ -0,0 +1,52 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSingleUsePrivateKey(t *testing.T) {
+	k, pub, err := otssha256.GenerateSingleUse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Used() {
+		t.Fatalf("new key is marked as used")
+	}
+	raw := k.Unsafe()
+	if len(raw) != otssha256.PrivateKeySize() {
+		t.Fatalf("wrong private key size: %d", len(raw))
+	}
+	pub2, err := otssha256.PublicKeyFromPrivate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pub, pub2) {
+		t.Fatalf("public key doesn't match private key")
+	}
+
+	msg := []byte(testMessage)
+	sig, err := k.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !otssha256.Verify(pub, msg, sig) {
+		t.Fatalf("failed to verify correct signature")
+	}
+	if !k.Used() {
+		t.Fatalf("key is not marked as used after signing")
+	}
+	if _, err := k.Sign(msg); err != ErrKeyUsed {
+		t.Fatalf("second Sign: expected ErrKeyUsed, got %v", err)
+	}
+	if k.Unsafe() != nil {
+		t.Fatalf("private key is accessible after use")
+	}
+	if !bytes.Equal(raw, make([]byte, len(raw))) {
+		t.Fatalf("private key bytes were not wiped")
+	}
+}
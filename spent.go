@@ -0,0 +1,51 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "sync"
+
+// SignatureTag returns a deterministic tag of the signature, which is
+// the hash of it. Applications can store tags of produced signatures to
+// detect duplicates.
+func (s *Scheme) SignatureTag(sig []byte) []byte {
+	h := s.hashFunc()
+	h.Write(sig)
+	return h.Sum(nil)
+}
+
+// SpentKeyTracker records public keys that have been used for signing,
+// so that an application can refuse to sign again with the same key.
+// It's an operational safety net against one-time key reuse: mark the
+// public key as used before signing with the corresponding private key.
+//
+// The zero value is an empty tracker ready to use. SpentKeyTracker is
+// safe for concurrent use by multiple goroutines.
+type SpentKeyTracker struct {
+	mu   sync.Mutex
+	used map[string]struct{}
+}
+
+// MarkUsed marks the public key as used. It returns ErrKeyUsed if the
+// key has already been marked.
+func (t *SpentKeyTracker) MarkUsed(pub PublicKey) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.used[string(pub)]; ok {
+		return ErrKeyUsed
+	}
+	if t.used == nil {
+		t.used = make(map[string]struct{})
+	}
+	t.used[string(pub)] = struct{}{}
+	return nil
+}
+
+// IsUsed reports whether the public key has been marked as used.
+func (t *SpentKeyTracker) IsUsed(pub PublicKey) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.used[string(pub)]
+	return ok
+}
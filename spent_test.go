@@ -0,0 +1,70 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestSignatureTag(t *testing.T) {
+	sig, err := otssha256.Sign(make(PrivateKey, otssha256.PrivateKeySize()), []byte(testMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag := otssha256.SignatureTag(sig)
+	if !bytes.Equal(tag, otssha256.SignatureTag(sig)) {
+		t.Fatalf("tags of the same signature differ")
+	}
+	sig[0] ^= 1
+	if bytes.Equal(tag, otssha256.SignatureTag(sig)) {
+		t.Fatalf("tags of different signatures are equal")
+	}
+}
+
+func TestSpentKeyTracker(t *testing.T) {
+	var tracker SpentKeyTracker
+	pub := PublicKey("public key 1")
+	if tracker.IsUsed(pub) {
+		t.Fatalf("new key is marked as used")
+	}
+	if err := tracker.MarkUsed(pub); err != nil {
+		t.Fatal(err)
+	}
+	if !tracker.IsUsed(pub) {
+		t.Fatalf("key is not marked as used")
+	}
+	if err := tracker.MarkUsed(pub); err != ErrKeyUsed {
+		t.Fatalf("expected ErrKeyUsed, got %v", err)
+	}
+	if tracker.IsUsed(PublicKey("public key 2")) {
+		t.Fatalf("other key is marked as used")
+	}
+}
+
+func TestSpentKeyTrackerConcurrent(t *testing.T) {
+	var tracker SpentKeyTracker
+	pub := PublicKey("public key")
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	successes := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if tracker.MarkUsed(pub) == nil {
+				mu.Lock()
+				successes++
+				mu.Unlock()
+			}
+			tracker.IsUsed(pub)
+		}()
+	}
+	wg.Wait()
+	if successes != 1 {
+		t.Fatalf("expected exactly one successful MarkUsed, got %d", successes)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// VerifyStream is like Verify, but reads the message from r until EOF
+// without loading it into memory. It returns an error if reading fails.
+func (s *Scheme) VerifyStream(publicKey PublicKey, r io.Reader, sig []byte) (bool, error) {
+	if s.lenPrefix {
+		return false, errors.New("wots: streaming verification is not supported with length prefix")
+	}
+	if !s.checkVerifySizes(publicKey, sig) {
+		return false, nil
+	}
+	var rh randomizedHash
+	rh.init(s.hashFunc(), s.randHash, sig[:s.randLen], make([]byte, s.randLen))
+	if _, err := io.Copy(&rh, r); err != nil {
+		return false, err
+	}
+	return s.verifyDigits(publicKey, s.appendDigits(nil, rh.appendDigest(nil)), sig), nil
+}
+
+// VerifyMultiReader is like VerifyStream, but reads the message split into
+// parts from the given readers in sequence, as if it was their
+// concatenation. It returns false and an error if reading any part fails.
+func (s *Scheme) VerifyMultiReader(publicKey PublicKey, sig []byte, parts ...io.Reader) (bool, error) {
+	return s.VerifyStream(publicKey, io.MultiReader(parts...), sig)
+}
+
+// VerifyFile is like Verify, but reads the message from the file at the
+// given path. It returns an error if the file cannot be read.
+func (s *Scheme) VerifyFile(publicKey PublicKey, path string, sig []byte) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	return s.VerifyStream(publicKey, f, sig)
+}
+
+// VerifyStreamPairs reads concatenated signatures from r and verifies each
+// of them against the public key and message returned by the next call to
+// items, until items returns false. It returns verification results in
+// order.
+//
+// It returns the results obtained so far and an error if reading fails,
+// r ends before all signatures are read or contains a truncated signature,
+// or r has data left after the last signature.
+func (s *Scheme) VerifyStreamPairs(r io.Reader, items func() (pub PublicKey, msg []byte, ok bool)) (results []bool, err error) {
+	sig := make([]byte, s.SignatureSize())
+	for {
+		pub, msg, ok := items()
+		if !ok {
+			break
+		}
+		if _, err := io.ReadFull(r, sig); err != nil {
+			switch err {
+			case io.EOF:
+				return results, errors.New("wots: signature stream ended before all signatures were read")
+			case io.ErrUnexpectedEOF:
+				return results, errors.New("wots: truncated signature in stream")
+			}
+			return results, err
+		}
+		results = append(results, s.Verify(pub, msg, sig))
+	}
+	var b [1]byte
+	if n, err := io.ReadFull(r, b[:]); n > 0 {
+		return results, errors.New("wots: signature stream has extra data")
+	} else if err != io.EOF {
+		return results, err
+	}
+	return results, nil
+}
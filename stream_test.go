@@ -0,0 +1,180 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+)
+
+func TestVerifyStream(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Message longer than a few randomization blocks, not aligned to them.
+	msg := bytes.Repeat([]byte(testMessage), 100)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := otssha256.VerifyStream(pub, iotest.OneByteReader(bytes.NewReader(msg)), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("failed to verify correct signature")
+	}
+	ok, err = otssha256.VerifyStream(pub, bytes.NewReader(msg[1:]), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf("verified wrong message")
+	}
+	readErr := errors.New("read error")
+	if _, err := otssha256.VerifyStream(pub, iotest.ErrReader(readErr), sig); err != readErr {
+		t.Fatalf("expected read error, got %v", err)
+	}
+}
+
+func TestVerifyMultiReader(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := bytes.Repeat([]byte(testMessage), 10)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !otssha256.Verify(pub, msg, sig) {
+		t.Fatalf("failed to verify correct signature in memory")
+	}
+	ok, err := otssha256.VerifyMultiReader(pub, sig,
+		bytes.NewReader(msg[:7]),
+		iotest.OneByteReader(bytes.NewReader(msg[7:100])),
+		bytes.NewReader(msg[100:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("failed to verify correct signature from parts")
+	}
+	ok, err = otssha256.VerifyMultiReader(pub, sig, bytes.NewReader(msg[:7]), bytes.NewReader(msg[8:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf("verified wrong message")
+	}
+	readErr := errors.New("read error")
+	ok, err = otssha256.VerifyMultiReader(pub, sig, bytes.NewReader(msg), iotest.ErrReader(readErr))
+	if ok || err != readErr {
+		t.Fatalf("expected false and read error, got %v, %v", ok, err)
+	}
+}
+
+func TestVerifyFile(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := bytes.Repeat([]byte(testMessage), 1000)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "message")
+	if err := os.WriteFile(path, msg, 0600); err != nil {
+		t.Fatal(err)
+	}
+	ok, err := otssha256.VerifyFile(pub, path, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok != otssha256.Verify(pub, msg, sig) || !ok {
+		t.Fatalf("failed to verify correct signature")
+	}
+	sig[0] ^= 1
+	ok, err = otssha256.VerifyFile(pub, path, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf("verified wrong signature")
+	}
+	if _, err := otssha256.VerifyFile(pub, filepath.Join(t.TempDir(), "missing"), sig); err == nil {
+		t.Fatalf("no error for missing file")
+	}
+}
+
+func TestVerifyStreamPairs(t *testing.T) {
+	const n = 4
+	var (
+		pubs []PublicKey
+		msgs [][]byte
+		buf  bytes.Buffer
+	)
+	for i := 0; i < n; i++ {
+		priv, pub, err := otssha256.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := []byte{byte(i)}
+		sig, err := otssha256.Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubs = append(pubs, pub)
+		msgs = append(msgs, msg)
+		buf.Write(sig)
+	}
+	msgs[2] = []byte("wrong message")
+	stream := buf.Bytes()
+	items := func(count int) func() (PublicKey, []byte, bool) {
+		i := 0
+		return func() (PublicKey, []byte, bool) {
+			if i == count {
+				return nil, nil, false
+			}
+			i++
+			return pubs[i-1], msgs[i-1], true
+		}
+	}
+	results, err := otssha256.VerifyStreamPairs(iotest.HalfReader(bytes.NewReader(stream)), items(n))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []bool{true, true, false, true}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(results))
+	}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Errorf("signature %d: expected %v, got %v", i, expected[i], results[i])
+		}
+	}
+
+	results, err = otssha256.VerifyStreamPairs(bytes.NewReader(stream[:len(stream)-1]), items(n))
+	if err == nil {
+		t.Fatalf("no error for truncated signature")
+	}
+	if len(results) != n-1 {
+		t.Fatalf("expected %d results before truncated signature, got %d", n-1, len(results))
+	}
+	if _, err := otssha256.VerifyStreamPairs(bytes.NewReader(stream), items(n-1)); err == nil {
+		t.Fatalf("no error for extra data")
+	}
+	if _, err := otssha256.VerifyStreamPairs(bytes.NewReader(stream), func() (PublicKey, []byte, bool) {
+		return pubs[0], msgs[0], true
+	}); err == nil {
+		t.Fatalf("no error for missing signatures")
+	}
+}
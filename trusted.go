@@ -0,0 +1,50 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"errors"
+)
+
+// TrustedPublicKey is a public key validated for use with a scheme, which
+// verifies signatures without checking the public key on each call.
+//
+// TrustedPublicKey is safe for concurrent use by multiple goroutines.
+type TrustedPublicKey struct {
+	scheme *Scheme
+	salt   []byte // L-tree salt, nil in the default mode
+	key    []byte // public key hash
+}
+
+// NewTrustedPublicKey returns a trusted public key for verifying signatures
+// with the scheme. The public key is copied. It returns an error if the
+// public key is nil or its size doesn't match the scheme.
+func (s *Scheme) NewTrustedPublicKey(publicKey PublicKey) (*TrustedPublicKey, error) {
+	if len(publicKey) == 0 {
+		return nil, errors.New("wots: public key is nil")
+	}
+	if len(publicKey) != s.PublicKeySize() {
+		return nil, errors.New("wots: public key size doesn't match the scheme")
+	}
+	salt, key := s.splitPublicKey(append(PublicKey(nil), publicKey...))
+	return &TrustedPublicKey{scheme: s, salt: salt, key: key}, nil
+}
+
+// Verify verifies the signature of message, and returns true iff the
+// signature is valid. The result is the same as returned by Scheme Verify
+// method with the public key.
+func (k *TrustedPublicKey) Verify(message, sig []byte) bool {
+	s := k.scheme
+	if len(sig) != s.SignatureSize() {
+		s.observeVerify(VerifyBadSignatureSize)
+		return false
+	}
+	if !bytes.Equal(s.recoverMessageKey(nil, k.salt, message, sig), k.key) {
+		s.observeVerify(VerifyMismatch)
+		return false
+	}
+	return true
+}
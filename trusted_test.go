@@ -0,0 +1,71 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "testing"
+
+func TestTrustedPublicKey(t *testing.T) {
+	for _, s := range []*Scheme{otssha256, otssha256LTree} {
+		priv, pub, err := s.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		k, err := s.NewTrustedPublicKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := []byte(testMessage)
+		sig, err := s.Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bad := append([]byte(nil), sig...)
+		bad[len(bad)-1] ^= 1
+		for _, test := range []struct {
+			msg, sig []byte
+		}{
+			{msg, sig},
+			{msg[1:], sig},
+			{msg, bad},
+			{msg, sig[1:]},
+		} {
+			if ok, expected := k.Verify(test.msg, test.sig), s.Verify(pub, test.msg, test.sig); ok != expected {
+				t.Fatalf("expected %v, got %v", expected, ok)
+			}
+		}
+		if _, err := s.NewTrustedPublicKey(pub[1:]); err == nil {
+			t.Fatalf("no error for wrong public key size")
+		}
+		if _, err := s.NewTrustedPublicKey(nil); err == nil {
+			t.Fatalf("no error for nil public key")
+		}
+	}
+}
+
+func BenchmarkTrustedPublicKey(b *testing.B) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		b.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("Scheme", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			otssha256.Verify(pub, msg, sig)
+		}
+	})
+	b.Run("TrustedPublicKey", func(b *testing.B) {
+		k, err := otssha256.NewTrustedPublicKey(pub)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for i := 0; i < b.N; i++ {
+			k.Verify(msg, sig)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrVanityNotFound is returned by GenerateVanityKeyPair when no public key
+// with the requested prefix was found within the given number of tries.
+var ErrVanityNotFound = errors.New("wots: vanity public key not found")
+
+// GenerateVanityKeyPair repeatedly generates key pairs until it finds one
+// with the public key starting with prefix, and returns it. It makes at
+// most maxTries attempts, searching in parallel on all available CPUs, and
+// returns ErrVanityNotFound if none of them succeeds. The scheme's random
+// reader must be safe for concurrent use.
+//
+// Each byte of prefix makes the search about 256 times longer.
+func (s *Scheme) GenerateVanityKeyPair(prefix []byte, maxTries int) (PrivateKey, PublicKey, error) {
+	if len(prefix) > s.PublicKeySize() {
+		return nil, nil, errors.New("wots: vanity prefix is longer than public key")
+	}
+	var (
+		tries    int64
+		once     sync.Once
+		found    int32
+		wg       sync.WaitGroup
+		priv     PrivateKey
+		pub      PublicKey
+		firstErr error
+	)
+	for n := runtime.GOMAXPROCS(0); n > 0; n-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			kc := s.NewKeygenContext()
+			defer kc.WipePrivate()
+			for atomic.LoadInt32(&found) == 0 && atomic.AddInt64(&tries, 1) <= int64(maxTries) {
+				publicKey, err := kc.Generate()
+				if err != nil {
+					once.Do(func() { firstErr = err })
+					atomic.StoreInt32(&found, 1)
+					return
+				}
+				if bytes.HasPrefix(publicKey, prefix) {
+					once.Do(func() {
+						priv = append(PrivateKey(nil), kc.PrivateKey()...)
+						pub = append(PublicKey(nil), publicKey...)
+					})
+					atomic.StoreInt32(&found, 1)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+	if priv == nil {
+		return nil, nil, ErrVanityNotFound
+	}
+	return priv, pub, nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGenerateVanityKeyPair(t *testing.T) {
+	prefix := []byte{0x42}
+	// Probability of failure is (255/256)^5000 < 1e-8.
+	priv, pub, err := otssha256.GenerateVanityKeyPair(prefix, 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(pub, prefix) {
+		t.Fatalf("public key %x doesn't start with %x", pub, prefix)
+	}
+	expected, err := otssha256.PublicKeyFromPrivate(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pub, expected) {
+		t.Fatalf("public key doesn't match private key")
+	}
+}
+
+func TestGenerateVanityKeyPairNotFound(t *testing.T) {
+	// With zero reader all keys are the same.
+	s := NewScheme(otssha256.hashFunc, zeroReader)
+	_, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.GenerateVanityKeyPair([]byte{^pub[0]}, 3); err != ErrVanityNotFound {
+		t.Fatalf("expected ErrVanityNotFound, got %v", err)
+	}
+	if _, _, err := s.GenerateVanityKeyPair(make([]byte, 33), 3); err == nil {
+		t.Fatalf("no error for too long prefix")
+	}
+}
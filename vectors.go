@@ -0,0 +1,27 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import "errors"
+
+// GenerateInteropVector returns a test vector for the scheme, which can be
+// used to check interoperability of implementations: the private and public
+// key pair derived from seed with DeriveKeyPair (index 0), and the signature
+// of message made with the given randomization parameter r, which must be
+// RandomizerLen bytes long.
+//
+// The result is deterministic and doesn't depend on the scheme's random
+// reader.
+func GenerateInteropVector(s *Scheme, seed, message, r []byte) (priv, pub, sig []byte, err error) {
+	if len(r) != s.randLen {
+		return nil, nil, nil, errors.New("wots: randomization parameter size doesn't match the scheme")
+	}
+	privateKey, publicKey, err := s.DeriveKeyPair(seed, 0)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sig = s.newSigner().signWithRandomizer(make([]byte, 0, s.SignatureSize()), privateKey, r, message)
+	return privateKey, publicKey, sig, nil
+}
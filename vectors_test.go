@@ -0,0 +1,47 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+)
+
+func TestGenerateInteropVector(t *testing.T) {
+	schemes := []*Scheme{
+		otssha256,
+		otssha256LTree,
+		NewScheme(sha512.New, rand.Reader),
+		NewScheme2(sha256.New, sha512.New, rand.Reader, WithW(4)),
+	}
+	msg := []byte(testMessage)
+	for _, s := range schemes {
+		seed := bytes.Repeat([]byte{1}, s.SeedSize())
+		r := bytes.Repeat([]byte{2}, s.SeedSize())
+		priv, pub, sig, err := GenerateInteropVector(s, seed, msg, r)
+		if err != nil {
+			t.Fatalf("%s: %s", s.ID(), err)
+		}
+		if !s.Verify(pub, msg, sig) {
+			t.Fatalf("%s: failed to verify generated vector", s.ID())
+		}
+		if !bytes.Equal(sig[:len(r)], r) {
+			t.Fatalf("%s: signature doesn't start with randomization parameter", s.ID())
+		}
+		priv2, pub2, sig2, err := GenerateInteropVector(s, seed, msg, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(priv, priv2) || !bytes.Equal(pub, pub2) || !bytes.Equal(sig, sig2) {
+			t.Fatalf("%s: vector is not reproducible", s.ID())
+		}
+		if _, _, _, err := GenerateInteropVector(s, seed, msg, r[1:]); err == nil {
+			t.Fatalf("%s: no error for wrong randomization parameter size", s.ID())
+		}
+	}
+}
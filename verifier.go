@@ -0,0 +1,68 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"bytes"
+	"hash"
+)
+
+// VerifierState verifies signatures with the scheme, reusing hash instances
+// and scratch buffers across calls, which avoids allocations in tight
+// verification loops.
+//
+// VerifierState is not safe for concurrent use: each goroutine must use
+// its own state.
+type VerifierState struct {
+	scheme    *Scheme
+	blockHash hash.Hash
+	keyHash   keyHasher // also hashes messages
+	tmp       []byte    // randomized hashing block
+	digest    []byte    // message digest
+	digits    []int     // message digest digits with checksum
+	endpoints []byte    // chain endpoints
+	key       []byte    // recovered public key hash
+}
+
+// NewVerifierState returns a new verifier state for the given scheme.
+func NewVerifierState(s *Scheme) *VerifierState {
+	return &VerifierState{
+		scheme:    s,
+		blockHash: s.chainFunc(),
+		keyHash:   keyHasher{h: s.hashFunc(), tag: s.paramsTag},
+		tmp:       make([]byte, s.randLen),
+		digest:    make([]byte, 0, s.digestSize),
+		digits:    make([]int, 0, s.numChains),
+		endpoints: s.newEndpoints(),
+		key:       make([]byte, 0, s.digestSize),
+	}
+}
+
+// Verify verifies the signature of message using the public key, and
+// returns true iff the signature is valid. The result is the same as
+// returned by Scheme Verify method.
+func (v *VerifierState) Verify(publicKey PublicKey, message, sig []byte) bool {
+	s := v.scheme
+	if !s.checkVerifySizes(publicKey, sig) {
+		return false
+	}
+	salt, key := s.splitPublicKey(publicKey)
+	v.digest = s.appendMessageDigest(v.digest[:0], v.keyHash.h, v.tmp, sig[:s.randLen], message)
+	v.digits = s.appendDigits(v.digits[:0], v.digest)
+	v.endpoints = v.endpoints[:0]
+	chains := sig[s.randLen:]
+	for i, d := range v.digits {
+		v.endpoints = s.appendChain(v.endpoints, v.blockHash, chains[:s.blockSize], i, d, s.chainLen-d)
+		chains = chains[s.blockSize:]
+	}
+	v.keyHash.reset(salt)
+	v.keyHash.addEndpoints(v.endpoints, s.blockSize)
+	v.key = v.keyHash.appendSum(v.key[:0])
+	if !bytes.Equal(v.key, key) {
+		s.observeVerify(VerifyMismatch)
+		return false
+	}
+	return true
+}
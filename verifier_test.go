@@ -0,0 +1,84 @@
+// Copyright 2017 Dmitry Chestnykh. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wots
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestVerifierState(t *testing.T) {
+	schemes := []*Scheme{
+		otssha256,
+		otssha256LTree,
+		NewScheme(sha256.New, rand.Reader, WithParamBinding()),
+		NewScheme(sha256.New, rand.Reader, WithoutRandomizedHashing()),
+	}
+	for _, s := range schemes {
+		v := NewVerifierState(s)
+		for i := 0; i < 3; i++ {
+			priv, pub, err := s.GenerateKeyPair()
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg := []byte(testMessage)
+			sig, err := s.Sign(priv, msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !v.Verify(pub, msg, sig) {
+				t.Fatalf("failed to verify correct signature")
+			}
+			if v.Verify(pub, msg[1:], sig) {
+				t.Fatalf("verified wrong message")
+			}
+			if v.Verify(pub, msg, sig[1:]) {
+				t.Fatalf("verified signature of wrong size")
+			}
+		}
+	}
+}
+
+func TestVerifierStateAllocs(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewVerifierState(otssha256)
+	if n := testing.AllocsPerRun(10, func() { v.Verify(pub, msg, sig) }); n != 0 {
+		t.Fatalf("expected no allocations, got %v", n)
+	}
+}
+
+func BenchmarkVerifierState(b *testing.B) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		b.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("Scheme", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			otssha256.Verify(pub, msg, sig)
+		}
+	})
+	b.Run("VerifierState", func(b *testing.B) {
+		v := NewVerifierState(otssha256)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			v.Verify(pub, msg, sig)
+		}
+	})
+}
@@ -10,12 +10,12 @@
 // signatures (even on quantum computer, provided that it can't break the
 // underlying hash function).
 //
-// Implementation details
+// # Implementation details
 //
-// Cost/size trade-off parameter w=8 bits, which means that public key
-// generation takes (n+2)*256+1 hash function evaluations, where n is hash
-// output size in bytes. Similarly, on average, signing or verifying a single
-// message take 1+((n+2)*255)/2 evaluations.
+// Cost/size trade-off parameter w=8 bits by default (see WithW), which means
+// that public key generation takes (n+2)*256+1 hash function evaluations,
+// where n is hash output size in bytes. Similarly, on average, signing or
+// verifying a single message take 1+((n+2)*255)/2 evaluations.
 //
 // Message hash is calculated with randomization as specified in NIST
 // SP-800-106 "Randomized Hashing for Digital Signatures", with length
@@ -25,40 +25,393 @@ package wots
 
 import (
 	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"hash"
 	"io"
 )
 
+// Supported hash function output sizes in bytes.
+const (
+	MinHashSize = 16
+	MaxHashSize = 128
+)
+
+// isHashSize reports whether n is a supported hash function output size.
+func isHashSize(n int) bool {
+	return n >= MinHashSize && n <= MaxHashSize
+}
+
+// ErrNilPrivateKey is returned when the private key is nil or empty, which
+// usually means that a key variable was not initialized.
+var ErrNilPrivateKey = errors.New("wots: private key is nil")
+
 // Scheme represents one-time signature signing/verification configuration.
 type Scheme struct {
-	blockSize int
-	hashFunc  func() hash.Hash
-	rand      io.Reader
+	blockSize  int // chain hash output size
+	digestSize int // message hash output size
+	randLen    int // randomization parameter size
+	randOpts   int // randomizer options applied, for detecting conflicts
+	randHash   RandHash
+	lenPrefix  bool // message length is hashed before message
+	hashFunc   func() hash.Hash
+	chainFunc  func() hash.Hash
+	rand       io.Reader
+	ltree      bool   // public key is a salted L-tree root
+	bindParams bool   // parameters are hashed into public key
+	paramsTag  []byte // encoded parameters, if bindParams is set
+	csFirst    bool   // checksum chains come before message digest chains
+
+	chainStart  func(block []byte, index int) []byte // private block transform, may be nil
+	chainExec   func(in []byte, times int) []byte    // chain hashing executor, may be nil
+	chainHasher ChainHasher                          // addressed chain hashing, may be nil
+
+	verifyObserver func(VerifyReason) // called on Verify failures, may be nil
+
+	w         int // bits per digit
+	chainLen  int // 1 << w, number of hashing steps in a chain
+	numDigits int // number of message digest digits
+	numChains int // number of message digest and checksum digits
+}
+
+// Option configures a scheme.
+type Option func(*Scheme)
+
+// WithW returns an option, which sets the cost/size trade-off parameter w,
+// the number of message digest bits signed by each chain. Larger w makes
+// keys and signatures shorter, but key generation, signing, and
+// verification exponentially slower. The default is 8.
+//
+// Supported values are 1, 2, 4, 8, and 16; WithW panics on other values.
+// For w=16 the message hash output size must be even, otherwise scheme
+// constructors panic.
+func WithW(w int) Option {
+	if !isSupportedW(w) {
+		panic("wots: unsupported parameter w")
+	}
+	return func(s *Scheme) { s.w = w }
+}
+
+// RandHash is a randomized message hashing construction.
+type RandHash int
+
+const (
+	// RandHashSP800106 is the randomized hashing construction
+	// from NIST SP-800-106. It's the default.
+	RandHashSP800106 RandHash = iota
+
+	// RandHashPrefix is the simple randomized hashing construction,
+	// which hashes the randomization parameter followed by the message:
+	// H(r ‖ message).
+	RandHashPrefix
+)
+
+// WithRandHash returns an option, which sets the randomized message hashing
+// construction. Signatures made with one construction don't verify with
+// the other one.
+//
+// RandHashPrefix is provided to interoperate with implementations which
+// don't support SP-800-106. Its security depends on the hash function
+// being resistant to attacks on the message following a random prefix,
+// which doesn't hold for some hash functions as well as it does for
+// SP-800-106.
+//
+// WithRandHash panics on unknown constructions.
+func WithRandHash(construction RandHash) Option {
+	if construction != RandHashSP800106 && construction != RandHashPrefix {
+		panic("wots: unknown randomized hashing construction")
+	}
+	return func(s *Scheme) { s.randHash = construction }
+}
+
+// WithLengthPrefix returns an option, which makes the message digest
+// include the message length: the 64-bit big-endian length of message is
+// hashed before it. Even without it, padding makes digests of messages,
+// such as an empty one and a single zero byte, differ; the length prefix
+// makes message encoding unambiguous independently of the hashing
+// construction.
+//
+// Since the length must be known before hashing, streaming operations,
+// such as VerifyStream and PrefixState, return errors for schemes with
+// length prefix.
+func WithLengthPrefix() Option {
+	return func(s *Scheme) { s.lenPrefix = true }
+}
+
+// WithChecksumFirst returns an option, which places checksum chains before
+// message digest chains in private keys and signatures, as some other
+// implementations do. By default, checksum chains come last. Public keys
+// are the same for both orders, since all chains are computed the same
+// way, but signatures are not.
+func WithChecksumFirst() Option {
+	return func(s *Scheme) { s.csFirst = true }
+}
+
+// WithoutRandomizedHashing returns an option, which disables randomized
+// hashing: messages are hashed with the plain message hash function and
+// signatures don't include the randomization parameter, which makes them
+// shorter by the message hash output size.
+//
+// WARNING: Without randomized hashing the security of signatures depends
+// on collision resistance of the hash function instead of its weaker
+// properties: whoever can find two messages with the same hash and get
+// one of them signed, obtains a signature of the other one. Use it only
+// if signed messages are not chosen by untrusted parties, or the hash
+// function is known to be collision resistant.
+func WithoutRandomizedHashing() Option {
+	return func(s *Scheme) {
+		s.randLen = 0
+		s.randOpts |= randOptDisabled
+	}
+}
+
+// Randomizer options for Scheme.randOpts.
+const (
+	randOptDisabled = 1 << iota // WithoutRandomizedHashing
+	randOptLen                  // WithRandomizerLen
+)
+
+// WithChainStart returns an option, which sets the transform applied to
+// each private key block with the given chain index before hashing it in
+// PublicKeyFromPrivate and when signing. The transform must return a new
+// block of the same size without modifying the original one. The default
+// is identity.
+//
+// It allows experimenting with chain start derivation, for example, with
+// PRF-based or address-tweaked variants. Schemes with a chain start
+// transform have no ID and can't be marshaled with MarshalConfig.
+func WithChainStart(transform func(block []byte, index int) []byte) Option {
+	return func(s *Scheme) { s.chainStart = transform }
+}
+
+// WithChainExecutor returns an option, which sets the function used to
+// hash chain blocks instead of hashing them in process with the chain hash
+// function, for example, to offload this work to hardware accelerators.
+// The executor must return a new block, which is the input block hashed
+// the given number of times with the chain hash function H(...H(in)), or
+// a copy of it if times is 0. It's used for key generation, signing, and
+// verification.
+func WithChainExecutor(executor func(in []byte, times int) []byte) Option {
+	return func(s *Scheme) { s.chainExec = executor }
+}
+
+// appendChain is like appendHashBlock, but uses the chain hasher or the
+// chain executor if the scheme has them. The input is the value at the
+// given start position of the chain with the given index.
+func (s *Scheme) appendChain(dst []byte, h hash.Hash, in []byte, index, start, times int) []byte {
+	if s.chainHasher != nil {
+		return s.appendAddressedChain(dst, in, index, start, times)
+	}
+	if s.chainExec == nil {
+		return appendHashBlock(dst, h, in, times)
+	}
+	out := s.chainExec(in, times)
+	if len(out) != s.blockSize {
+		panic("wots: chain executor returned wrong block size")
+	}
+	return append(dst, out...)
+}
+
+// startBlock returns the start of chain with the given index
+// for the private key block.
+func (s *Scheme) startBlock(block []byte, index int) []byte {
+	if s.chainStart == nil {
+		return block
+	}
+	out := s.chainStart(block, index)
+	if len(out) != s.blockSize {
+		panic("wots: chain start transform returned wrong block size")
+	}
+	return out
+}
+
+// WithRandomizerLen returns an option, which sets the size of
+// randomization parameter to n bytes instead of the message hash output
+// size. It panics if n is not between MinHashSize and MaxHashSize. It
+// conflicts with WithoutRandomizedHashing, and scheme constructors reject
+// schemes with both options.
+func WithRandomizerLen(n int) Option {
+	if !isHashSize(n) {
+		panic("wots: unsupported randomization parameter size")
+	}
+	return func(s *Scheme) {
+		s.randLen = n
+		s.randOpts |= randOptLen
+	}
+}
+
+// isSupportedW reports whether w is a supported value of parameter w.
+func isSupportedW(w int) bool {
+	return w == 1 || w == 2 || w == 4 || w == 8 || w == 16
+}
+
+// chainCounts returns the number of message digits and the total number of
+// message and checksum digits for the given digest size and parameter w.
+func chainCounts(digestSize, w int) (numDigits, numChains int) {
+	numDigits = digestSize * 8 / w
+	// Checksum is a sum of (1<<w - digit) for each digit,
+	// encoded in base 1<<w digits.
+	maxSum := numDigits << uint(w)
+	numChecksum := 1
+	for 1<<uint(w*numChecksum) <= maxSum {
+		numChecksum++
+	}
+	return numDigits, numDigits + numChecksum
 }
 
 // NewScheme returns a new signing/verification scheme from the given function
 // returning hash.Hash type and a random byte reader (must be cryptographically
 // secure, such as crypto/rand.Reader).
 //
-// The hash function output size must have minimum 16 and maximum 128 bytes,
-// otherwise GenerateKeyPair method will always return error.
-func NewScheme(h func() hash.Hash, rand io.Reader) *Scheme {
-	return &Scheme{
-		blockSize: h().Size(),
-		hashFunc:  h,
-		rand:      rand,
+// The hash function output size must be between MinHashSize and MaxHashSize
+// bytes, otherwise GenerateKeyPair method will always return error.
+func NewScheme(h func() hash.Hash, rand io.Reader, opts ...Option) *Scheme {
+	return NewScheme2(h, h, rand, opts...)
+}
+
+// NewScheme2 is like NewScheme, but uses different hash functions for
+// message and public key hashing (h), and for hashing chains (chain).
+// Private key consists of one block of chain hash output size per chain.
+//
+// The output size of both hash functions must be between MinHashSize and
+// MaxHashSize bytes, otherwise GenerateKeyPair method will always return error.
+//
+// It panics if w is 16 and the message hash output size is odd, since
+// such digest can't be split into digits, or if options conflict, such as
+// WithoutRandomizedHashing and WithRandomizerLen.
+func NewScheme2(h, chain func() hash.Hash, rand io.Reader, opts ...Option) *Scheme {
+	s, err := newScheme2(h, chain, rand, opts...)
+	if err != nil {
+		panic(err.Error())
 	}
+	return s
+}
+
+// newScheme2 is like NewScheme2, but returns an error instead of panicking.
+func newScheme2(h, chain func() hash.Hash, rand io.Reader, opts ...Option) (*Scheme, error) {
+	s := &Scheme{
+		blockSize:  chain().Size(),
+		digestSize: h().Size(),
+		hashFunc:   h,
+		chainFunc:  chain,
+		rand:       rand,
+		randLen:    h().Size(),
+		w:          8,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.chainLen = 1 << uint(s.w)
+	s.numDigits, s.numChains = chainCounts(s.digestSize, s.w)
+	if s.randOpts == randOptDisabled|randOptLen {
+		return nil, errors.New("wots: WithRandomizerLen conflicts with WithoutRandomizedHashing")
+	}
+	if !s.digitsFit() {
+		return nil, errors.New("wots: message hash output size must be even for w=16")
+	}
+	if s.bindParams {
+		s.paramsTag = s.encodeParamsTag()
+	}
+	return s, nil
+}
+
+// NewSchemeChecked is like NewScheme2, but checks that the hash functions
+// are usable and returns an error if they are not: their output sizes must
+// be supported and stable across calls, Sum must return Size bytes, and
+// hashing the same input after Reset must produce the same output.
+func NewSchemeChecked(h, chain func() hash.Hash, rand io.Reader, opts ...Option) (*Scheme, error) {
+	if err := checkHashFunc(h); err != nil {
+		return nil, err
+	}
+	if err := checkHashFunc(chain); err != nil {
+		return nil, err
+	}
+	return newScheme2(h, chain, rand, opts...)
+}
+
+// checkHashFunc returns an error if the hash function is unusable.
+func checkHashFunc(f func() hash.Hash) error {
+	h := f()
+	size := h.Size()
+	if !isHashSize(size) {
+		return errors.New("wots: wrong hash output size")
+	}
+	if f().Size() != size {
+		return errors.New("wots: hash output size is not stable")
+	}
+	vector := []byte("wots hash check")
+	h.Write(vector)
+	d1 := h.Sum(nil)
+	h.Reset()
+	h.Write(vector)
+	d2 := h.Sum(nil)
+	if len(d1) != size {
+		return errors.New("wots: hash output length doesn't match its size")
+	}
+	if !bytes.Equal(d1, d2) {
+		return errors.New("wots: hash output after Reset differs")
+	}
+	return nil
+}
+
+// WithRand returns a copy of the scheme, which uses rand as the source of
+// randomness. All other parameters are the same as in the original scheme.
+func (s *Scheme) WithRand(rand io.Reader) *Scheme {
+	c := *s
+	c.rand = rand
+	return &c
 }
 
 // PrivateKeySize returns private key size in bytes.
-func (s *Scheme) PrivateKeySize() int { return (s.blockSize + 2) * s.blockSize }
+func (s *Scheme) PrivateKeySize() int { return s.numChains * s.blockSize }
 
 // PublicKeySize returns public key size in bytes.
-func (s *Scheme) PublicKeySize() int { return s.blockSize }
+func (s *Scheme) PublicKeySize() int {
+	if s.ltree {
+		return 2 * s.digestSize
+	}
+	return s.digestSize
+}
 
 // SignatureSize returns signature size in bytes.
-func (s *Scheme) SignatureSize() int { return (s.blockSize+2)*s.blockSize + s.blockSize }
+func (s *Scheme) SignatureSize() int { return s.randLen + s.numChains*s.blockSize }
+
+// SignatureSizeFor returns the size in bytes of the signature of message.
+// Signatures in all supported modes have a fixed size, so it ignores the
+// message and returns SignatureSize; callers preallocating buffers can use
+// it to stay correct if variable-size modes are added.
+func (s *Scheme) SignatureSizeFor(message []byte) int { return s.SignatureSize() }
+
+// IsValidSignatureLength reports whether n is the signature size of the
+// scheme. It allows rejecting malformed input before parsing it.
+func (s *Scheme) IsValidSignatureLength(n int) bool { return n == s.SignatureSize() }
+
+// IsValidPublicKeyLength reports whether n is the public key size of the
+// scheme. It allows rejecting malformed input before parsing it.
+func (s *Scheme) IsValidPublicKeyLength(n int) bool { return n == s.PublicKeySize() }
+
+// RandomizerOffset returns the offset of randomization parameter in signature.
+func (s *Scheme) RandomizerOffset() int { return 0 }
+
+// RandomizerLen returns the length of randomization parameter in signature.
+// It's zero if randomized hashing is disabled.
+func (s *Scheme) RandomizerLen() int { return s.randLen }
+
+// NumChains returns the number of hash chains in signature, which is the
+// number of message digest digits plus the number of checksum digits.
+func (s *Scheme) NumChains() int { return s.numChains }
+
+// ChainOffset returns the offset of the i-th chain block in signature.
+// Blocks are stored one after another, with ChainOffset(NumChains())
+// equal to SignatureSize. ChainOffset panics if i is out of this range.
+func (s *Scheme) ChainOffset(i int) int {
+	if i < 0 || i > s.numChains {
+		panic("wots: chain index out of range")
+	}
+	return s.RandomizerOffset() + s.RandomizerLen() + i*s.blockSize
+}
 
 // PublicKey represents a public key.
 type PublicKey []byte
@@ -66,21 +419,66 @@ type PublicKey []byte
 // PrivateKey represents a private key.
 type PrivateKey []byte
 
+// Equal reports whether k and other are equal private keys. The time it
+// takes depends only on the lengths of the keys, not on their contents.
+func (k PrivateKey) Equal(other PrivateKey) bool {
+	return subtle.ConstantTimeCompare(k, other) == 1
+}
+
 // hashBlock returns in hashed the given number of times: H(...H(in)).
 // If times is 0, returns a copy of input without hashing it.
 func hashBlock(h hash.Hash, in []byte, times int) (out []byte) {
-	out = append(out, in...)
+	return appendHashBlock(nil, h, in, times)
+}
+
+// appendHashBlock is like hashBlock, but appends the result to dst,
+// hashing in place without allocating if dst has enough capacity.
+func appendHashBlock(dst []byte, h hash.Hash, in []byte, times int) []byte {
+	n := len(dst)
+	dst = append(dst, in...)
 	for i := 0; i < times; i++ {
 		h.Reset()
-		h.Write(out)
-		out = h.Sum(out[:0])
+		h.Write(dst[n:])
+		dst = appendHashSum(dst[:n], h)
+	}
+	return dst
+}
+
+// appendHashSum appends the hash of data written to h to dst and returns
+// the result. It passes an empty slice to Sum and copies its result, so
+// that it works correctly with hash implementations, which return a new
+// slice instead of appending to the given one. For implementations which
+// append, the copy is in place, since dst is grown to fit the hash first.
+func appendHashSum(dst []byte, h hash.Hash) []byte {
+	n := len(dst)
+	if size := h.Size(); cap(dst)-n < size {
+		grown := make([]byte, n, n+size)
+		copy(grown, dst)
+		dst = grown
 	}
-	return
+	return append(dst, h.Sum(dst[n:n])...)
+}
+
+// validHashSizes reports whether hash function output sizes are supported
+// and the message digest can be split into digits of w bits.
+func (s *Scheme) validHashSizes() bool {
+	return isHashSize(s.blockSize) && isHashSize(s.digestSize) && s.digitsFit()
+}
+
+// digitsFit reports whether the message digest splits into whole digits.
+func (s *Scheme) digitsFit() bool {
+	return s.digestSize*8%s.w == 0
 }
 
 // GenerateKeyPair generates a new private and public key pair.
 func (s *Scheme) GenerateKeyPair() (PrivateKey, PublicKey, error) {
-	if s.blockSize < 16 || s.blockSize > 128 {
+	return s.generateKeyPair(nil)
+}
+
+// generateKeyPair is like GenerateKeyPair, but calls check, if it's not
+// nil, before hashing each chain.
+func (s *Scheme) generateKeyPair(check chainCheck) (PrivateKey, PublicKey, error) {
+	if !s.validHashSizes() {
 		return nil, nil, errors.New("wots: wrong hash output size")
 	}
 	// Generate random private key.
@@ -88,67 +486,244 @@ func (s *Scheme) GenerateKeyPair() (PrivateKey, PublicKey, error) {
 	if _, err := io.ReadFull(s.rand, privateKey); err != nil {
 		return nil, nil, err
 	}
-	publicKey, err := s.PublicKeyFromPrivate(privateKey)
+	publicKey, err := s.publicKeyFromPrivate(privateKey, check)
 	if err != nil {
 		return nil, nil, err
 	}
 	return privateKey, publicKey, nil
 }
 
+// checkPrivateKey returns an error if the private key is nil or empty,
+// or its size doesn't match the scheme.
+func (s *Scheme) checkPrivateKey(privateKey PrivateKey) error {
+	if len(privateKey) == 0 {
+		return ErrNilPrivateKey
+	}
+	if len(privateKey) != s.PrivateKeySize() {
+		return errors.New("wots: private key size doesn't match the scheme")
+	}
+	return nil
+}
+
 // PublicKeyFromPrivate returns a public key corresponding to the given private key.
 func (s *Scheme) PublicKeyFromPrivate(privateKey PrivateKey) (PublicKey, error) {
-	if len(privateKey) != s.PrivateKeySize() {
-		return nil, errors.New("wots: private key size doesn't match the scheme")
+	if err := s.checkPrivateKey(privateKey); err != nil {
+		return nil, err
+	}
+	return s.publicKeyFromPrivate(privateKey, nil)
+}
+
+// publicKeyFromPrivate is like PublicKeyFromPrivate, but calls check, if
+// it's not nil, before hashing each chain. The private key size must be
+// already checked.
+func (s *Scheme) publicKeyFromPrivate(privateKey PrivateKey, check chainCheck) (PublicKey, error) {
+	// appendPublicKeyCheck resets the key hasher, so it's not created with
+	// newKeyHasher, which is not inlined and would make it escape to the heap.
+	keyHash := &keyHasher{h: s.hashFunc(), tag: s.paramsTag}
+	return s.appendPublicKeyCheck(nil, privateKey, s.chainFunc(), keyHash, s.newEndpoints(), check)
+}
+
+// newEndpoints returns an empty buffer for chain endpoints.
+func (s *Scheme) newEndpoints() []byte {
+	return make([]byte, 0, s.numChains*s.blockSize)
+}
+
+// chainCheck is called with the chain index before hashing each chain,
+// for example, to check for cancellation. A non-nil error aborts the
+// operation and is returned from it.
+type chainCheck func(i int) error
+
+// appendPublicKey appends the public key created from the private key to
+// dst and returns the result, using the given hashes and the endpoints
+// buffer returned by newEndpoints. The private key size must be already
+// checked.
+func (s *Scheme) appendPublicKey(dst []byte, privateKey PrivateKey, blockHash hash.Hash, keyHash *keyHasher, endpoints []byte) []byte {
+	dst, _ = s.appendPublicKeyCheck(dst, privateKey, blockHash, keyHash, endpoints, nil)
+	return dst
+}
+
+// appendPublicKeyCheck is like appendPublicKey, but calls check, if it's
+// not nil, before hashing each chain.
+//
+// Chain endpoints are calculated into a contiguous buffer first and then
+// hashed in one pass, which is friendlier to CPU caches.
+func (s *Scheme) appendPublicKeyCheck(dst []byte, privateKey PrivateKey, blockHash hash.Hash, keyHash *keyHasher, endpoints []byte, check chainCheck) ([]byte, error) {
+	var salt []byte
+	if s.ltree {
+		salt = s.publicKeySalt(privateKey)
 	}
+	endpoints = endpoints[:0]
+	for i := 0; i < s.numChains; i++ {
+		if check != nil {
+			if err := check(i); err != nil {
+				return nil, err
+			}
+		}
+		start := s.startBlock(privateKey[i*s.blockSize:(i+1)*s.blockSize], i)
+		endpoints = s.appendChain(endpoints, blockHash, start, i, 0, s.chainLen)
+	}
+	keyHash.reset(salt)
+	keyHash.addEndpoints(endpoints, s.blockSize)
+	dst = append(dst, salt...)
+	return keyHash.appendSum(dst), nil
+}
+
+// messageDigest returns a randomized digest of message.
+func (s *Scheme) messageDigest(r []byte, msg []byte) []byte {
+	return s.appendMessageDigest(nil, s.hashFunc(), make([]byte, len(r)), r, msg)
+}
 
-	// Create public key from private key.
-	keyHash := s.hashFunc()
-	blockHash := s.hashFunc()
-	for i := 0; i < len(privateKey); i += s.blockSize {
-		keyHash.Write(hashBlock(blockHash, privateKey[i:i+s.blockSize], 256))
+// appendMessageDigest is like messageDigest, but appends the result to dst,
+// hashes with h, and uses tmp, which must have the length of r, as
+// a scratch buffer.
+func (s *Scheme) appendMessageDigest(dst []byte, h hash.Hash, tmp, r, msg []byte) []byte {
+	var rh randomizedHash
+	rh.init(h, s.randHash, r, tmp)
+	if s.lenPrefix {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(len(msg)))
+		rh.Write(b[:])
 	}
-	return keyHash.Sum(nil), nil
+	rh.Write(msg)
+	return rh.appendDigest(dst)
 }
 
-// messageDigest returns a randomized digest of message with 2-byte checksum.
-func messageDigest(h hash.Hash, r []byte, msg []byte) []byte {
-	// Randomized hashing (NIST SP-800-106).
-	//
-	//  Padding: m = msg ‖ 0x80 [0x00...]
-	//  Hashing: H(r ‖ m1 ⊕ r, ..., mL ⊕ r ‖ rv_length_indicator)
-	//    where m1..mL are blocks of size len(r) of padded msg,
-	//    and rv_length_indicator is 16-byte big endian len(r).
-	//
+// randomizedHash calculates randomized message digest in a streaming way.
+// If the randomization parameter is empty, it calculates plain digest.
+// For RandHashPrefix, it calculates H(r ‖ msg).
+//
+// Randomized hashing (NIST SP-800-106):
+//
+//	Padding: m = msg ‖ 0x80 [0x00...]
+//	Hashing: H(r ‖ m1 ⊕ r, ..., mL ⊕ r ‖ rv_length_indicator)
+//	  where m1..mL are blocks of size len(r) of padded msg,
+//	  and rv_length_indicator is 16-byte big endian len(r).
+type randomizedHash struct {
+	h   hash.Hash
+	r   []byte
+	tmp []byte // current block
+	n   int    // number of bytes in tmp
+
+	plain bool // hash message as is after r
+}
+
+// init resets rh to hash a new message with h, the construction c, and the
+// randomization parameter r, using tmp, which must have the length of r,
+// for blocks.
+func (rh *randomizedHash) init(h hash.Hash, c RandHash, r, tmp []byte) {
+	rh.h = h
+	rh.r = r
+	rh.tmp = tmp
+	rh.n = 0
+	rh.plain = c == RandHashPrefix || len(r) == 0
+	h.Reset()
 	h.Write(r)
-	rlen := len(r)
-	tmp := make([]byte, rlen)
-	for len(msg) >= rlen {
-		for i, m := range msg[:rlen] {
-			tmp[i] = m ^ r[i]
+}
+
+// Write adds more message data to the digest. It never returns an error.
+func (rh *randomizedHash) Write(p []byte) (int, error) {
+	if rh.plain {
+		return rh.h.Write(p)
+	}
+	nn := len(p)
+	for len(p) > 0 {
+		n := copy(rh.tmp[rh.n:], p)
+		rh.n += n
+		p = p[n:]
+		if rh.n == len(rh.tmp) {
+			for i := range rh.tmp {
+				rh.tmp[i] ^= rh.r[i]
+			}
+			rh.h.Write(rh.tmp)
+			rh.n = 0
 		}
-		h.Write(tmp)
-		msg = msg[rlen:]
 	}
-	for i := range tmp {
+	return nn, nil
+}
+
+// appendDigest finishes hashing and appends the message digest to dst.
+func (rh *randomizedHash) appendDigest(dst []byte) []byte {
+	if rh.plain {
+		return appendHashSum(dst, rh.h)
+	}
+	tmp := rh.tmp
+	for i := rh.n; i < len(tmp); i++ {
 		tmp[i] = 0
 	}
-	copy(tmp, msg)
-	tmp[len(msg)] = 0x80
+	tmp[rh.n] = 0x80
 	for i := range tmp {
-		tmp[i] ^= r[i]
+		tmp[i] ^= rh.r[i]
 	}
-	h.Write(tmp)
+	rh.h.Write(tmp)
+	rlen := len(rh.r)
 	tmp[0] = uint8(rlen >> 8)
 	tmp[1] = uint8(rlen)
-	h.Write(tmp[:2])
-	d := h.Sum(nil)
+	rh.h.Write(tmp[:2])
+	return appendHashSum(dst, rh.h)
+}
+
+// messageDigits returns digits of the randomized message digest with checksum.
+func (s *Scheme) messageDigits(r, msg []byte) []int {
+	return s.appendDigits(make([]int, 0, s.numChains), s.messageDigest(r, msg))
+}
+
+// appendDigits splits the message digest d into digits of w bits,
+// appends them followed by checksum digits to dst, and returns the result.
+func (s *Scheme) appendDigits(dst []int, d []byte) []int {
+	n := len(dst)
+	if s.w == 16 {
+		for i := 0; i < len(d); i += 2 {
+			dst = append(dst, int(d[i])<<8|int(d[i+1]))
+		}
+	} else {
+		mask := s.chainLen - 1
+		for _, b := range d {
+			for shift := 8 - s.w; shift >= 0; shift -= s.w {
+				dst = append(dst, int(b)>>uint(shift)&mask)
+			}
+		}
+	}
+	dst = s.appendChecksum(dst, dst[n:])
+	if s.csFirst {
+		rotateDigits(dst[n:], s.numDigits)
+	}
+	return dst
+}
+
+// rotateDigits rotates digits left by k positions in place.
+func rotateDigits(digits []int, k int) {
+	reverseDigits(digits[:k])
+	reverseDigits(digits[k:])
+	reverseDigits(digits)
+}
+
+// reverseDigits reverses digits in place.
+func reverseDigits(digits []int) {
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+}
 
-	// Append checksum of digest bits.
-	var sum uint16
-	for _, v := range d {
-		sum += 256 - uint16(v)
+// splitDigits returns message digest digits and checksum digits of digits
+// with checksum in the scheme's chain order.
+func (s *Scheme) splitDigits(digits []int) (message, checksum []int) {
+	if s.csFirst {
+		n := s.numChains - s.numDigits
+		return digits[n:], digits[:n]
+	}
+	return digits[:s.numDigits], digits[s.numDigits:]
+}
+
+// appendChecksum appends checksum digits of message digest digits to dst.
+func (s *Scheme) appendChecksum(dst []int, digits []int) []int {
+	sum := 0
+	for _, v := range digits {
+		sum += s.chainLen - v
+	}
+	for i := s.numChains - s.numDigits - 1; i >= 0; i-- {
+		dst = append(dst, sum>>uint(i*s.w)&(s.chainLen-1))
 	}
-	return append(d, uint8(sum>>8), uint8(sum))
+	return dst
 }
 
 // Sign signs an arbitrary length message using the given private key and
@@ -157,43 +732,406 @@ func messageDigest(h hash.Hash, r []byte, msg []byte) []byte {
 // IMPORTANT: Do not use the same private key to sign more than one message!
 // It's a one-time signature.
 func (s *Scheme) Sign(privateKey PrivateKey, message []byte) (sig []byte, err error) {
-	if len(privateKey) != s.PrivateKeySize() {
-		return nil, errors.New("wots: private key size doesn't match the scheme")
+	return s.newSigner().sign(make([]byte, 0, s.SignatureSize()), privateKey, message)
+}
+
+// SignWithRandomizer is like Sign, but uses the given randomization
+// parameter r, which must be RandomizerLen bytes long, instead of reading
+// it from the scheme's random reader. It's intended to be used with r
+// returned by SignPlan; r must be generated by a cryptographically secure
+// random number generator.
+//
+// IMPORTANT: Do not use the same private key to sign more than one message!
+// It's a one-time signature.
+func (s *Scheme) SignWithRandomizer(privateKey PrivateKey, r, message []byte) ([]byte, error) {
+	if err := s.checkPrivateKey(privateKey); err != nil {
+		return nil, err
+	}
+	if len(r) != s.randLen {
+		return nil, errors.New("wots: randomization parameter size doesn't match the scheme")
+	}
+	return s.newSigner().signWithRandomizer(make([]byte, 0, s.SignatureSize()), privateKey, r, message), nil
+}
+
+// SignPlan reads a new randomization parameter from the scheme's random
+// reader, and returns it along with the message digits with checksum,
+// which determine how many times each chain will be hashed when signing
+// message with it. It doesn't need a private key, so no key is spent; to
+// sign the message according to the plan, call SignWithRandomizer with
+// the returned r.
+func (s *Scheme) SignPlan(message []byte) (digits []int, r []byte, err error) {
+	r = make([]byte, s.randLen)
+	if _, err := io.ReadFull(s.rand, r); err != nil {
+		return nil, nil, err
+	}
+	return s.messageDigits(r, message), r, nil
+}
+
+// SignInto is like Sign, but writes the signature into dst, which must be
+// at least SignatureSize bytes long, and returns the number of bytes written.
+//
+// IMPORTANT: Do not use the same private key to sign more than one message!
+// It's a one-time signature.
+func (s *Scheme) SignInto(privateKey PrivateKey, message []byte, dst []byte) (int, error) {
+	if len(dst) < s.SignatureSize() {
+		return 0, errors.New("wots: destination buffer is too small")
+	}
+	sig, err := s.newSigner().sign(dst[:0], privateKey, message)
+	if err != nil {
+		return 0, err
 	}
+	return len(sig), nil
+}
+
+// signer holds hash instances and scratch buffers used for signing,
+// so that they can be reused across signatures.
+type signer struct {
+	scheme    *Scheme
+	blockHash hash.Hash
+	msgHash   hash.Hash
+	r         []byte // randomization parameter
+	tmp       []byte // randomized hashing block
+	digest    []byte // message digest
+	digits    []int  // message digest digits with checksum
+}
+
+func (s *Scheme) newSigner() *signer {
+	return &signer{
+		scheme:    s,
+		blockHash: s.chainFunc(),
+		msgHash:   s.hashFunc(),
+		r:         make([]byte, s.randLen),
+		tmp:       make([]byte, s.randLen),
+		digest:    make([]byte, 0, s.digestSize),
+		digits:    make([]int, 0, s.numChains),
+	}
+}
+
+// sign appends the signature of message to sig and returns the result.
+func (sg *signer) sign(sig []byte, privateKey PrivateKey, message []byte) ([]byte, error) {
+	return sg.signCheck(sig, privateKey, message, nil)
+}
 
-	blockHash := s.hashFunc()
+// signCheck is like sign, but calls check, if it's not nil, before
+// hashing each chain.
+func (sg *signer) signCheck(sig []byte, privateKey PrivateKey, message []byte, check chainCheck) ([]byte, error) {
+	if err := sg.scheme.checkPrivateKey(privateKey); err != nil {
+		return nil, err
+	}
 
 	// Generate message randomization parameter.
-	r := make([]byte, s.blockSize)
-	if _, err := io.ReadFull(s.rand, r); err != nil {
+	if _, err := io.ReadFull(sg.scheme.rand, sg.r); err != nil {
 		return nil, err
 	}
+	sg.digest = sg.scheme.appendMessageDigest(sg.digest[:0], sg.msgHash, sg.tmp, sg.r, message)
+	return sg.signDigestCheck(sig, privateKey, sg.r, sg.digest, check)
+}
+
+// signWithRandomizer appends the signature of message made with the
+// randomization parameter r to sig and returns the result. The private
+// key size must be already checked.
+func (sg *signer) signWithRandomizer(sig []byte, privateKey PrivateKey, r, message []byte) []byte {
+	sg.digest = sg.scheme.appendMessageDigest(sg.digest[:0], sg.msgHash, sg.tmp, r, message)
+	return sg.signDigest(sig, privateKey, r, sg.digest)
+}
+
+// signDigest appends the signature of the randomized message digest made
+// with the randomization parameter r to sig and returns the result. The
+// private key size must be already checked.
+func (sg *signer) signDigest(sig []byte, privateKey PrivateKey, r, digest []byte) []byte {
+	sig, _ = sg.signDigestCheck(sig, privateKey, r, digest, nil)
+	return sig
+}
+
+// signDigestCheck is like signDigest, but calls check, if it's not nil,
+// before hashing each chain.
+func (sg *signer) signDigestCheck(sig []byte, privateKey PrivateKey, r, digest []byte, check chainCheck) ([]byte, error) {
+	s := sg.scheme
 
 	// Prepend randomization parameter to signature.
 	sig = append(sig, r...)
 
-	for _, v := range messageDigest(s.hashFunc(), r, message) {
-		sig = append(sig, hashBlock(blockHash, privateKey[:s.blockSize], int(v))...)
+	sg.digits = s.appendDigits(sg.digits[:0], digest)
+	for i, v := range sg.digits {
+		if check != nil {
+			if err := check(i); err != nil {
+				return nil, err
+			}
+		}
+		sig = s.appendChain(sig, sg.blockHash, s.startBlock(privateKey[:s.blockSize], i), i, 0, v)
 		privateKey = privateKey[s.blockSize:]
 	}
-	return
+	return sig, nil
 }
 
 // Verify verifies the signature of message using the public key,
-// and returns true iff the signature is valid.
+// and returns true iff the signature is valid. A nil or empty public key
+// is reported to the verify observer as VerifyNilPublicKey.
 //
 // Note: verification time depends on message and signature.
 func (s *Scheme) Verify(publicKey PublicKey, message []byte, sig []byte) bool {
-	if len(publicKey) != s.PublicKeySize() || len(sig) != s.SignatureSize() {
+	if !s.checkVerifySizes(publicKey, sig) {
+		return false
+	}
+	salt, key := s.splitPublicKey(publicKey)
+	if !bytes.Equal(s.recoverMessageKey(nil, salt, message, sig), key) {
+		s.observeVerify(VerifyMismatch)
+		return false
+	}
+	return true
+}
+
+// checkVerifySizes reports whether the public key and signature sizes
+// match the scheme, reporting the reason to the verify observer if they
+// don't. It's shared by verification entry points, so that observers see
+// the same results from all of them.
+func (s *Scheme) checkVerifySizes(publicKey PublicKey, sig []byte) bool {
+	switch {
+	case len(publicKey) == 0:
+		s.observeVerify(VerifyNilPublicKey)
+	case len(publicKey) != s.PublicKeySize():
+		s.observeVerify(VerifyBadPublicKeySize)
+	case len(sig) != s.SignatureSize():
+		s.observeVerify(VerifyBadSignatureSize)
+	default:
+		return true
+	}
+	return false
+}
+
+// verifyDigits verifies the signature using the given message digest
+// digits with checksum, reporting a mismatch to the verify observer.
+// Sizes of public key and signature must be already checked.
+func (s *Scheme) verifyDigits(publicKey PublicKey, digits []int, sig []byte) bool {
+	salt, key := s.splitPublicKey(publicKey)
+	if !bytes.Equal(s.recoverKey(salt, digits, sig), key) {
+		s.observeVerify(VerifyMismatch)
+		return false
+	}
+	return true
+}
+
+// recoverKey returns the public key hash (without salt) recovered from
+// the signature using the message digest digits and the L-tree salt.
+func (s *Scheme) recoverKey(salt []byte, digits []int, sig []byte) []byte {
+	return s.appendRecoverKey(nil, salt, digits, sig)
+}
+
+// appendRecoverKey is like recoverKey, but appends the result to dst.
+func (s *Scheme) appendRecoverKey(dst, salt []byte, digits []int, sig []byte) []byte {
+	return s.appendRecoverKeyHash(dst, s.newKeyHasher(salt), digits, sig)
+}
+
+// recoverMessageKey is like appendRecoverKey, but calculates the message
+// digits itself, using the same hash instance for the message digest and
+// the public key hash.
+func (s *Scheme) recoverMessageKey(dst, salt, message, sig []byte) []byte {
+	dst, _ = s.recoverMessageKeyCheck(dst, salt, message, sig, nil)
+	return dst
+}
+
+// recoverMessageKeyCheck is like recoverMessageKey, but calls check, if
+// it's not nil, before hashing each chain.
+func (s *Scheme) recoverMessageKeyCheck(dst, salt, message, sig []byte, check chainCheck) ([]byte, error) {
+	h := s.hashFunc()
+	r := sig[:s.randLen]
+	digest := s.appendMessageDigest(nil, h, make([]byte, len(r)), r, message)
+	digits := s.appendDigits(make([]int, 0, s.numChains), digest)
+	keyHash := &keyHasher{h: h, tag: s.paramsTag}
+	keyHash.reset(salt)
+	return s.appendRecoverKeyHashCheck(dst, keyHash, digits, sig, check)
+}
+
+// appendRecoverKeyHash is like appendRecoverKey, but folds the endpoints
+// into the given key hasher.
+func (s *Scheme) appendRecoverKeyHash(dst []byte, keyHash *keyHasher, digits []int, sig []byte) []byte {
+	dst, _ = s.appendRecoverKeyHashCheck(dst, keyHash, digits, sig, nil)
+	return dst
+}
+
+// appendRecoverKeyHashCheck is like appendRecoverKeyHash, but calls check,
+// if it's not nil, before hashing each chain.
+func (s *Scheme) appendRecoverKeyHashCheck(dst []byte, keyHash *keyHasher, digits []int, sig []byte, check chainCheck) ([]byte, error) {
+	sig = sig[s.randLen:]
+	blockHash := s.chainFunc()
+	endpoints := s.newEndpoints()
+	for i, v := range digits {
+		if check != nil {
+			if err := check(i); err != nil {
+				return nil, err
+			}
+		}
+		endpoints = s.appendChain(endpoints, blockHash, sig[:s.blockSize], i, v, s.chainLen-v)
+		sig = sig[s.blockSize:]
+	}
+	keyHash.addEndpoints(endpoints, s.blockSize)
+	return keyHash.appendSum(dst), nil
+}
+
+// RecoverPublicKey returns the public key, under which the signature of
+// message is valid. Any correctly sized signature produces some public key,
+// so the caller must compare the result with a trusted public key.
+//
+// It returns an error if the signature size is wrong, or the scheme uses
+// the L-tree mode, in which the public key salt is not recoverable.
+func (s *Scheme) RecoverPublicKey(message, sig []byte) (PublicKey, error) {
+	if len(sig) != s.SignatureSize() {
+		return nil, errors.New("wots: signature size doesn't match the scheme")
+	}
+	if s.ltree {
+		return nil, errors.New("wots: public key is not recoverable in L-tree mode")
+	}
+	return s.recoverMessageKey(nil, nil, message, sig), nil
+}
+
+// RecoverPublicKeyInto is like RecoverPublicKey, but writes the public key
+// into dst, which must be PublicKeySize bytes long.
+func (s *Scheme) RecoverPublicKeyInto(message, sig, dst []byte) error {
+	if len(dst) != s.PublicKeySize() {
+		return errors.New("wots: destination size doesn't match public key size")
+	}
+	if len(sig) != s.SignatureSize() {
+		return errors.New("wots: signature size doesn't match the scheme")
+	}
+	if s.ltree {
+		return errors.New("wots: public key is not recoverable in L-tree mode")
+	}
+	s.recoverMessageKey(dst[:0], nil, message, sig)
+	return nil
+}
+
+// RecoverPublicKeyPrefix returns the first n bytes of the public key
+// recovered with RecoverPublicKey. It can be used to check the signature
+// against a short commitment to the public key before obtaining the full key.
+func (s *Scheme) RecoverPublicKeyPrefix(message, sig []byte, n int) ([]byte, error) {
+	if n < 0 || n > s.PublicKeySize() {
+		return nil, errors.New("wots: wrong public key prefix length")
+	}
+	publicKey, err := s.RecoverPublicKey(message, sig)
+	if err != nil {
+		return nil, err
+	}
+	return publicKey[:n], nil
+}
+
+// MessageDigits returns digits of the randomized message digest with
+// checksum, which determine how many times each chain is hashed when
+// signing message with the randomization parameter r, which is stored in
+// signature at RandomizerOffset and has RandomizerLen bytes. Digits are
+// in the order of chains: checksum digits follow message digest digits,
+// or precede them in schemes with WithChecksumFirst.
+func (s *Scheme) MessageDigits(r, message []byte) ([]int, error) {
+	if len(r) != s.randLen {
+		return nil, errors.New("wots: randomization parameter size doesn't match the scheme")
+	}
+	return s.messageDigits(r, message), nil
+}
+
+// VerifyDigits verifies the signature using the public key and message
+// digits, as returned by MessageDigits, instead of a message. It returns
+// false if the number of digits is wrong, any digit is out of range, or
+// checksum digits don't match message digest digits.
+func (s *Scheme) VerifyDigits(publicKey PublicKey, digits []int, sig []byte) bool {
+	if !s.checkVerifySizes(publicKey, sig) {
+		return false
+	}
+	if !s.validDigits(digits) {
+		s.observeVerify(VerifyMismatch)
+		return false
+	}
+	return s.verifyDigits(publicKey, digits, sig)
+}
+
+// validDigits reports whether digits have the right number, are in range,
+// and checksum digits match message digest digits.
+func (s *Scheme) validDigits(digits []int) bool {
+	if len(digits) != s.numChains {
+		return false
+	}
+	for _, v := range digits {
+		if v < 0 || v >= s.chainLen {
+			return false
+		}
+	}
+	msgDigits, checksum := s.splitDigits(digits)
+	for i, v := range s.appendChecksum(nil, msgDigits) {
+		if checksum[i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifyRaw verifies the signature using the public key and the number of
+// hashing steps needed to complete each chain (1<<w - digit) instead of
+// a message. Unlike VerifyDigits, it doesn't check checksum digits, so
+// it's only suitable for testing and debugging interoperability with
+// other implementations.
+func (s *Scheme) VerifyRaw(publicKey PublicKey, sig []byte, completions []int) bool {
+	if !s.checkVerifySizes(publicKey, sig) {
+		return false
+	}
+	if len(completions) != s.numChains {
+		s.observeVerify(VerifyMismatch)
 		return false
 	}
-	d := messageDigest(s.hashFunc(), sig[:s.blockSize], message)
-	sig = sig[s.blockSize:]
-	keyHash := s.hashFunc()
-	blockHash := s.hashFunc()
-	for _, v := range d {
-		keyHash.Write(hashBlock(blockHash, sig[:s.blockSize], 256-int(v)))
+	digits := make([]int, len(completions))
+	for i, c := range completions {
+		if c < 1 || c > s.chainLen {
+			s.observeVerify(VerifyMismatch)
+			return false
+		}
+		digits[i] = s.chainLen - c
+	}
+	return s.verifyDigits(publicKey, digits, sig)
+}
+
+// VerifyConstantTime is like Verify, but its running time doesn't depend
+// on the message digest: each chain is hashed the full 1<<w times and the
+// required intermediate value is selected in constant time. This makes
+// verification about two times slower on average.
+//
+// Use it where an adversary controls the message and can observe
+// verification timing.
+func (s *Scheme) VerifyConstantTime(publicKey PublicKey, message []byte, sig []byte) bool {
+	if !s.checkVerifySizes(publicKey, sig) {
+		return false
+	}
+	digits := s.messageDigits(sig[:s.randLen], message)
+	sig = sig[s.randLen:]
+	salt, key := s.splitPublicKey(publicKey)
+	keyHash := s.newKeyHasher(salt)
+	blockHash := s.chainFunc()
+	out := make([]byte, s.blockSize)
+	cur := make([]byte, s.blockSize)
+	for index, v := range digits {
+		times := s.chainLen - v
+		copy(cur, sig[:s.blockSize])
+		subtle.ConstantTimeCopy(subtle.ConstantTimeEq(int32(times), 0), out, cur)
+		for i := 1; i <= s.chainLen; i++ {
+			// Steps past the chain end are dummy; keep their position at
+			// the last step, so that chain hashers never see positions
+			// outside of the chain.
+			pos := subtle.ConstantTimeSelect(subtle.ConstantTimeLessOrEq(v+i, s.chainLen), v+i-1, s.chainLen-1)
+			cur = s.appendChain(cur[:0], blockHash, cur, index, pos, 1)
+			subtle.ConstantTimeCopy(subtle.ConstantTimeEq(int32(times), int32(i)), out, cur)
+		}
+		keyHash.add(out)
 		sig = sig[s.blockSize:]
 	}
-	return bytes.Equal(keyHash.Sum(nil), publicKey)
+	if subtle.ConstantTimeCompare(keyHash.sum(), key) != 1 {
+		s.observeVerify(VerifyMismatch)
+		return false
+	}
+	return true
+}
+
+// VerifyTimingProfile returns a human-readable description of timing
+// properties of Verify and VerifyConstantTime for this scheme.
+func (s *Scheme) VerifyTimingProfile() string {
+	chains, n := s.numChains, s.chainLen
+	return fmt.Sprintf("Verify: variable time depending on message digest, "+
+		"%d to %d hash evaluations (%d on average); "+
+		"VerifyConstantTime: constant time, %d hash evaluations",
+		2+chains, 2+chains*n, 2+chains*(n+1)/2, 2+chains*n)
 }
@@ -8,7 +8,11 @@ import (
 	"bytes"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
 	"encoding/base64"
+	"hash"
+	"strconv"
 	"testing"
 )
 
@@ -96,6 +100,1037 @@ func TestVerify(t *testing.T) {
 
 }
 
+func TestVerifyConstantTime(t *testing.T) {
+	pk, err := base64.StdEncoding.DecodeString(testPublicKey)
+	if err != nil {
+		t.Fatalf("decoding public key: %s", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(testSig)
+	if err != nil {
+		t.Fatalf("decoding signature: %s", err)
+	}
+	msg := []byte(testMessage)
+	if !otssha256.VerifyConstantTime(pk, msg, sig) {
+		t.Fatalf("failed to verify correct signature")
+	}
+	if otssha256.VerifyConstantTime(pk, msg[1:], sig) {
+		t.Fatalf("verified wrong message")
+	}
+	sig[1] = 0
+	if otssha256.VerifyConstantTime(pk, msg, sig) {
+		t.Fatalf("verified wrong signature")
+	}
+	if otssha256.VerifyConstantTime(pk, msg, sig[1:]) {
+		t.Fatalf("verified signature of wrong size")
+	}
+}
+
+func TestVerifyDigits(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digits, err := otssha256.MessageDigits(sig[:otssha256.SeedSize()], msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(digits) != 34 {
+		t.Fatalf("expected 34 digits, got %d", len(digits))
+	}
+	if otssha256.VerifyDigits(pub, digits, sig) != otssha256.Verify(pub, msg, sig) {
+		t.Fatalf("VerifyDigits and Verify disagree")
+	}
+	if !otssha256.VerifyDigits(pub, digits, sig) {
+		t.Fatalf("failed to verify correct signature")
+	}
+	if otssha256.VerifyDigits(pub, digits[1:], sig) {
+		t.Fatalf("verified with wrong number of digits")
+	}
+	wrong := append([]int(nil), digits...)
+	wrong[0] = 256
+	if otssha256.VerifyDigits(pub, wrong, sig) {
+		t.Fatalf("verified with out of range digit")
+	}
+	wrong[0] = (digits[0] + 1) % 256
+	if otssha256.VerifyDigits(pub, wrong, sig) {
+		t.Fatalf("verified with wrong checksum")
+	}
+	otherDigits, err := otssha256.MessageDigits(sig[:otssha256.SeedSize()], msg[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otssha256.VerifyDigits(pub, otherDigits, sig) != otssha256.Verify(pub, msg[1:], sig) {
+		t.Fatalf("VerifyDigits and Verify disagree on wrong message")
+	}
+	if _, err := otssha256.MessageDigits(sig[:1], msg); err == nil {
+		t.Fatalf("no error for wrong randomization parameter size")
+	}
+}
+
+func TestPrivateKeyEqual(t *testing.T) {
+	priv, _, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	same := append(PrivateKey(nil), priv...)
+	if !priv.Equal(same) {
+		t.Errorf("equal keys are not equal")
+	}
+	same[len(same)-1] ^= 1
+	if priv.Equal(same) {
+		t.Errorf("different keys are equal")
+	}
+	if priv.Equal(priv[:len(priv)-1]) {
+		t.Errorf("keys of different lengths are equal")
+	}
+}
+
+func TestSignPlan(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	digits, r, err := otssha256.SignPlan(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(digits) != otssha256.NumChains() {
+		t.Fatalf("expected %d digits, got %d", otssha256.NumChains(), len(digits))
+	}
+	sig, err := otssha256.SignWithRandomizer(priv, r, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !otssha256.Verify(pub, msg, sig) {
+		t.Fatalf("signature verification failed")
+	}
+	if !bytes.Equal(sig[:otssha256.RandomizerLen()], r) {
+		t.Fatalf("signature doesn't use planned randomization parameter")
+	}
+	if !otssha256.VerifyDigits(pub, digits, sig) {
+		t.Fatalf("signature doesn't match planned digits")
+	}
+	if _, err := otssha256.SignWithRandomizer(priv, r[1:], msg); err == nil {
+		t.Fatalf("no error for wrong randomization parameter size")
+	}
+	if _, err := otssha256.SignWithRandomizer(priv[1:], r, msg); err == nil {
+		t.Fatalf("no error for wrong private key size")
+	}
+}
+
+func TestSignInto(t *testing.T) {
+	priv, pub, err := otssha256Insecure.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256Insecure.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := make([]byte, otssha256Insecure.SignatureSize()+10)
+	n, err := otssha256Insecure.SignInto(priv, msg, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != otssha256Insecure.SignatureSize() {
+		t.Fatalf("expected %d bytes written, got %d", otssha256Insecure.SignatureSize(), n)
+	}
+	if !bytes.Equal(sig, dst[:n]) {
+		t.Fatalf("SignInto output differs from Sign")
+	}
+	if !otssha256Insecure.Verify(pub, msg, dst[:n]) {
+		t.Fatalf("failed to verify correct signature")
+	}
+	if _, err := otssha256Insecure.SignInto(priv, msg, dst[:n-1]); err == nil {
+		t.Fatalf("no error for too small buffer")
+	}
+}
+
+func TestMixedHashes(t *testing.T) {
+	// Chain hash output (64 bytes) differs from message digest (32 bytes).
+	s := NewScheme2(sha256.New, sha512.New, rand.Reader)
+	if s.PrivateKeySize() != 34*64 {
+		t.Fatalf("private key size: expected %d, got %d", 34*64, s.PrivateKeySize())
+	}
+	if s.PublicKeySize() != 32 {
+		t.Fatalf("public key size: expected 32, got %d", s.PublicKeySize())
+	}
+	if s.SignatureSize() != 32+34*64 {
+		t.Fatalf("signature size: expected %d, got %d", 32+34*64, s.SignatureSize())
+	}
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(priv) != s.PrivateKeySize() || len(pub) != s.PublicKeySize() {
+		t.Fatalf("wrong key sizes: %d, %d", len(priv), len(pub))
+	}
+	pub2, err := s.PublicKeyFromPrivate(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pub, pub2) {
+		t.Fatalf("expected %x, got %x", pub, pub2)
+	}
+	msg := []byte(testMessage)
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != s.SignatureSize() {
+		t.Fatalf("signature size: expected %d, got %d", s.SignatureSize(), len(sig))
+	}
+	if !s.Verify(pub, msg, sig) {
+		t.Fatalf("failed to verify correct signature")
+	}
+	if !s.VerifyConstantTime(pub, msg, sig) {
+		t.Fatalf("failed to verify correct signature in constant time")
+	}
+	if s.Verify(pub, msg[1:], sig) {
+		t.Fatalf("verified wrong message")
+	}
+	sig[len(sig)-1] ^= 1
+	if s.Verify(pub, msg, sig) {
+		t.Fatalf("verified wrong signature")
+	}
+	var text PrivateKey
+	b, _ := priv.MarshalText()
+	if err := text.UnmarshalText(b); err != nil {
+		t.Fatalf("unmarshaling private key: %s", err)
+	}
+	var textSig Signature
+	b, _ = Signature(sig).MarshalText()
+	if err := textSig.UnmarshalText(b); err != nil {
+		t.Fatalf("unmarshaling signature: %s", err)
+	}
+}
+
+// xofHash is a hash.Hash of arbitrary output size based on SHAKE256.
+type xofHash struct {
+	size int
+	buf  []byte
+}
+
+func newXOFHash(size int) func() hash.Hash {
+	return func() hash.Hash { return &xofHash{size: size} }
+}
+
+func (h *xofHash) Write(p []byte) (int, error) {
+	h.buf = append(h.buf, p...)
+	return len(p), nil
+}
+
+func (h *xofHash) Sum(b []byte) []byte { return append(b, sha3.SumSHAKE256(h.buf, h.size)...) }
+func (h *xofHash) Reset()              { h.buf = h.buf[:0] }
+func (h *xofHash) Size() int           { return h.size }
+func (h *xofHash) BlockSize() int      { return 136 }
+
+// brokenHash is a hash.Hash, which doesn't reset its state.
+type brokenHash struct{ hash.Hash }
+
+func (h brokenHash) Reset() {}
+
+// replacingHash is a hash.Hash, which returns a new slice from Sum
+// instead of appending to the given one.
+type replacingHash struct{ hash.Hash }
+
+func (h replacingHash) Sum(b []byte) []byte { return h.Hash.Sum(nil) }
+
+func TestReplacingSum(t *testing.T) {
+	replacing := func() hash.Hash { return replacingHash{sha256.New()} }
+	for _, opts := range [][]Option{nil, {WithLTree()}} {
+		s := NewScheme(replacing, zeroReader, opts...)
+		expected := NewScheme(sha256.New, zeroReader, opts...)
+		priv, pub, err := s.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedPub, err := expected.PublicKeyFromPrivate(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(pub, expectedPub) {
+			t.Fatalf("public key differs: expected %x, got %x", expectedPub, pub)
+		}
+		msg := []byte(testMessage)
+		sig, err := s.Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedSig, err := expected.Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, expectedSig) {
+			t.Fatalf("signature differs")
+		}
+		if !s.Verify(pub, msg, sig) {
+			t.Fatalf("failed to verify signature")
+		}
+	}
+}
+
+func TestNewSchemeChecked(t *testing.T) {
+	if _, err := NewSchemeChecked(sha256.New, sha512.New, rand.Reader, WithW(4)); err != nil {
+		t.Fatal(err)
+	}
+	var calls int
+	unstable := func() hash.Hash {
+		calls++
+		return newXOFHash(16 + calls)()
+	}
+	for name, f := range map[string]func() hash.Hash{
+		"zero size": newXOFHash(0),
+		"too large": newXOFHash(MaxHashSize + 1),
+		"unstable":  unstable,
+		"no reset":  func() hash.Hash { return brokenHash{sha256.New()} },
+	} {
+		if _, err := NewSchemeChecked(sha256.New, f, rand.Reader); err == nil {
+			t.Errorf("%s: no error for broken chain hash", name)
+		}
+		if _, err := NewSchemeChecked(f, sha256.New, rand.Reader); err == nil {
+			t.Errorf("%s: no error for broken message hash", name)
+		}
+	}
+	if _, err := NewSchemeChecked(newXOFHash(17), sha256.New, rand.Reader, WithW(16)); err == nil {
+		t.Errorf("no error for digest size not divisible into digits")
+	}
+}
+
+func TestHashSizeLimits(t *testing.T) {
+	for _, size := range []int{MinHashSize, MaxHashSize} {
+		s := NewScheme(newXOFHash(size), rand.Reader)
+		priv, pub, err := s.GenerateKeyPair()
+		if err != nil {
+			t.Fatalf("%d: %s", size, err)
+		}
+		msg := []byte(testMessage)
+		sig, err := s.Sign(priv, msg)
+		if err != nil {
+			t.Fatalf("%d: %s", size, err)
+		}
+		if !s.Verify(pub, msg, sig) {
+			t.Fatalf("%d: failed to verify correct signature", size)
+		}
+	}
+	for _, size := range []int{MinHashSize - 1, MaxHashSize + 1} {
+		s := NewScheme(newXOFHash(size), rand.Reader)
+		if _, _, err := s.GenerateKeyPair(); err == nil {
+			t.Fatalf("%d: expected error", size)
+		}
+		if _, _, err := s.DeriveKeyPair(make([]byte, size), 0); err == nil {
+			t.Fatalf("%d: expected error from DeriveKeyPair", size)
+		}
+	}
+	s := NewScheme2(sha256.New, newXOFHash(MaxHashSize+1), rand.Reader)
+	if _, _, err := s.GenerateKeyPair(); err == nil {
+		t.Fatalf("expected error for wrong chain hash size")
+	}
+}
+
+func TestVerifyRaw(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digits, err := otssha256.MessageDigits(sig[:32], msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	completions := make([]int, len(digits))
+	for i, v := range digits {
+		completions[i] = 256 - v
+	}
+	if otssha256.VerifyRaw(pub, sig, completions) != otssha256.Verify(pub, msg, sig) {
+		t.Fatalf("VerifyRaw and Verify disagree")
+	}
+	if !otssha256.VerifyRaw(pub, sig, completions) {
+		t.Fatalf("failed to verify correct signature")
+	}
+	if otssha256.VerifyRaw(pub, sig, completions[1:]) {
+		t.Fatalf("verified with wrong number of completions")
+	}
+	bad := append([]int(nil), completions...)
+	bad[0] = 0
+	if otssha256.VerifyRaw(pub, sig, bad) {
+		t.Fatalf("verified with out of range completion")
+	}
+	bad[0] = 257
+	if otssha256.VerifyRaw(pub, sig, bad) {
+		t.Fatalf("verified with out of range completion")
+	}
+	bad[0] = completions[0]%256 + 1
+	if otssha256.VerifyRaw(pub, sig, bad) {
+		t.Fatalf("verified with wrong completion")
+	}
+}
+
+func TestRecoverPublicKey(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := otssha256.RecoverPublicKey(msg, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(recovered, pub) {
+		t.Fatalf("expected %x, got %x", pub, recovered)
+	}
+	prefix, err := otssha256.RecoverPublicKeyPrefix(msg, sig, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(prefix, recovered[:8]) {
+		t.Fatalf("prefix %x doesn't match recovered key %x", prefix, recovered)
+	}
+	other, err := otssha256.RecoverPublicKeyPrefix(msg[1:], sig, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(prefix, other) {
+		t.Fatalf("recovered the same prefix for wrong message")
+	}
+	if _, err := otssha256.RecoverPublicKeyPrefix(msg, sig, 33); err == nil {
+		t.Fatalf("no error for too long prefix")
+	}
+	if _, err := otssha256.RecoverPublicKey(msg, sig[1:]); err == nil {
+		t.Fatalf("no error for wrong signature size")
+	}
+	if _, err := otssha256LTree.RecoverPublicKey(msg, make([]byte, otssha256LTree.SignatureSize())); err == nil {
+		t.Fatalf("no error for L-tree mode")
+	}
+}
+
+func TestSignatureSizeFor(t *testing.T) {
+	for _, s := range []*Scheme{
+		otssha256,
+		NewScheme(sha256.New, rand.Reader, WithoutRandomizedHashing()),
+	} {
+		priv, _, err := s.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, msg := range []string{"", testMessage} {
+			sig, err := s.Sign(priv, []byte(msg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := s.SignatureSizeFor([]byte(msg)); n != len(sig) {
+				t.Errorf("expected %d, got %d", len(sig), n)
+			}
+		}
+	}
+}
+
+func TestIsValidLength(t *testing.T) {
+	for _, s := range []*Scheme{otssha256, otssha256LTree} {
+		sigSize, pubSize := s.SignatureSize(), s.PublicKeySize()
+		if !s.IsValidSignatureLength(sigSize) {
+			t.Errorf("signature length %d is not valid", sigSize)
+		}
+		if s.IsValidSignatureLength(sigSize-1) || s.IsValidSignatureLength(sigSize+1) {
+			t.Errorf("off-by-one signature length is valid")
+		}
+		if !s.IsValidPublicKeyLength(pubSize) {
+			t.Errorf("public key length %d is not valid", pubSize)
+		}
+		if s.IsValidPublicKeyLength(pubSize-1) || s.IsValidPublicKeyLength(pubSize+1) {
+			t.Errorf("off-by-one public key length is valid")
+		}
+	}
+}
+
+func TestWithChainExecutor(t *testing.T) {
+	var calls int
+	executor := func(in []byte, times int) []byte {
+		calls++
+		return hashBlock(sha256.New(), in, times)
+	}
+	s := NewScheme(sha256.New, zeroReader, WithChainExecutor(executor))
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultPub, err := otssha256Insecure.PublicKeyFromPrivate(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pub, defaultPub) {
+		t.Fatalf("public key differs from default")
+	}
+	msg := []byte(testMessage)
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultSig, err := otssha256Insecure.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, defaultSig) {
+		t.Fatalf("signature differs from default")
+	}
+	calls = 0
+	if !s.Verify(pub, msg, sig) {
+		t.Fatalf("signature verification failed")
+	}
+	if calls != s.NumChains() {
+		t.Fatalf("expected %d executor calls, got %d", s.NumChains(), calls)
+	}
+	if !s.VerifyConstantTime(pub, msg, sig) {
+		t.Fatalf("constant-time signature verification failed")
+	}
+}
+
+func TestMessageEncodingDistinct(t *testing.T) {
+	msgs := [][]byte{{}, {0x00}, {0x80}, {0x00, 0x00}}
+	for _, s := range []*Scheme{
+		otssha256,
+		NewScheme(sha256.New, rand.Reader, WithLengthPrefix()),
+		NewScheme(sha256.New, rand.Reader, WithLengthPrefix(), WithRandHash(RandHashPrefix)),
+	} {
+		r := make([]byte, s.RandomizerLen())
+		seen := make(map[string]bool)
+		for _, msg := range msgs {
+			d := string(s.messageDigest(r, msg))
+			if seen[d] {
+				t.Fatalf("%s: digest of %x is not distinct", s.ID(), msg)
+			}
+			seen[d] = true
+		}
+		for i, msg := range msgs {
+			priv, pub, err := s.GenerateKeyPair()
+			if err != nil {
+				t.Fatal(err)
+			}
+			sig, err := s.SignWithRandomizer(priv, r, msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for j, other := range msgs {
+				if ok := s.Verify(pub, other, sig); ok != (i == j) {
+					t.Fatalf("%s: signature of %x: verification of %x returned %v", s.ID(), msg, other, ok)
+				}
+			}
+		}
+	}
+}
+
+func TestWithLengthPrefix(t *testing.T) {
+	s := NewScheme(sha256.New, rand.Reader, WithLengthPrefix())
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Verify(pub, msg, sig) {
+		t.Fatalf("signature verification failed")
+	}
+	// Length prefix changes the digest.
+	r := sig[:s.RandomizerLen()]
+	if bytes.Equal(s.messageDigest(r, msg), otssha256.messageDigest(r, msg)) {
+		t.Fatalf("length prefix doesn't change digest")
+	}
+	if s.ID() != "wots-sha256-lenprefix" {
+		t.Fatalf("expected wots-sha256-lenprefix, got %q", s.ID())
+	}
+	if _, err := s.VerifyStream(pub, bytes.NewReader(msg), sig); err == nil {
+		t.Fatalf("no error for streaming verification with length prefix")
+	}
+	if _, err := s.PrefixState(msg); err == nil {
+		t.Fatalf("no error for prefix state with length prefix")
+	}
+}
+
+func TestNilKeys(t *testing.T) {
+	msg := []byte(testMessage)
+	if _, err := otssha256.Sign(nil, msg); err != ErrNilPrivateKey {
+		t.Fatalf("Sign: expected %v, got %v", ErrNilPrivateKey, err)
+	}
+	if _, err := otssha256.SignWithRandomizer(PrivateKey{}, make([]byte, 32), msg); err != ErrNilPrivateKey {
+		t.Fatalf("SignWithRandomizer: expected %v, got %v", ErrNilPrivateKey, err)
+	}
+	if _, err := otssha256.PublicKeyFromPrivate(nil); err != ErrNilPrivateKey {
+		t.Fatalf("PublicKeyFromPrivate: expected %v, got %v", ErrNilPrivateKey, err)
+	}
+	if _, err := otssha256.Sign(make(PrivateKey, 1), msg); err == nil || err == ErrNilPrivateKey {
+		t.Fatalf("wrong private key size: expected size error, got %v", err)
+	}
+	state, err := otssha256.PrefixState([]byte("prefix"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast := NewSHA256Scheme(rand.Reader)
+	for name, f := range map[string]func() error{
+		"SignIndexedDeterministic": func() error {
+			_, err := otssha256.SignIndexedDeterministic(nil, 0, msg)
+			return err
+		},
+		"PrepareSign": func() error {
+			_, _, err := otssha256.PrepareSign(nil)
+			return err
+		},
+		"RandomizerGuard.Sign": func() error {
+			_, err := NewRandomizerGuard(otssha256, 1).Sign(nil, msg)
+			return err
+		},
+		"SignWithPrefix": func() error {
+			_, err := otssha256.SignWithPrefix(nil, state, msg)
+			return err
+		},
+		"SHA256Scheme.Sign": func() error {
+			_, err := fast.Sign(nil, msg)
+			return err
+		},
+		"SHA256Scheme.PublicKeyFromPrivate": func() error {
+			_, err := fast.PublicKeyFromPrivate(nil)
+			return err
+		},
+	} {
+		if err := f(); err != ErrNilPrivateKey {
+			t.Fatalf("%s: expected %v, got %v", name, ErrNilPrivateKey, err)
+		}
+	}
+
+	s := NewScheme(sha256.New, rand.Reader)
+	var reasons []VerifyReason
+	s.SetVerifyObserver(func(r VerifyReason) { reasons = append(reasons, r) })
+	priv, _, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Verify(nil, msg, sig) {
+		t.Fatalf("verified signature with nil public key")
+	}
+	if len(reasons) != 1 || reasons[0] != VerifyNilPublicKey {
+		t.Fatalf("expected %v, got %v", VerifyNilPublicKey, reasons)
+	}
+}
+
+func TestWithChecksumFirst(t *testing.T) {
+	s := NewScheme(sha256.New, rand.Reader, WithChecksumFirst())
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Verify(pub, msg, sig) {
+		t.Fatalf("signature verification failed")
+	}
+	if otssha256.Verify(pub, msg, sig) {
+		t.Fatalf("checksum-last scheme verified checksum-first signature")
+	}
+	sig2, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Verify(pub, msg, sig2) {
+		t.Fatalf("checksum-first scheme verified checksum-last signature")
+	}
+
+	// Digits are the same, rotated.
+	r := sig[:s.RandomizerLen()]
+	first, err := s.MessageDigits(r, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, err := otssha256.MessageDigits(r, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := s.NumChains() - s.numDigits
+	for i := range first {
+		if first[(i+n)%len(first)] != last[i] {
+			t.Fatalf("digits are not rotated: %v, %v", first, last)
+		}
+	}
+	if !s.VerifyDigits(pub, first, sig) {
+		t.Fatalf("failed to verify digits")
+	}
+	if s.VerifyDigits(pub, last, sig) {
+		t.Fatalf("verified digits in wrong order")
+	}
+	if s.ID() != "wots-sha256-csfirst" {
+		t.Fatalf("expected wots-sha256-csfirst, got %q", s.ID())
+	}
+	s2, err := SchemeByID(s.ID(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s2.Verify(pub, msg, sig) {
+		t.Fatalf("scheme from ID failed to verify signature")
+	}
+}
+
+func TestRandHashPrefix(t *testing.T) {
+	s := NewScheme(sha256.New, rand.Reader, WithRandHash(RandHashPrefix))
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Verify(pub, msg, sig) {
+		t.Fatalf("signature verification failed")
+	}
+	var buf bytes.Buffer
+	buf.WriteString(testMessage)
+	if ok, err := s.VerifyStream(pub, &buf, sig); err != nil || !ok {
+		t.Fatalf("stream signature verification failed: %v", err)
+	}
+	// Digits come from H(r ‖ message).
+	r := sig[:s.RandomizerLen()]
+	h := sha256.Sum256(append(append([]byte(nil), r...), msg...))
+	digits, err := s.MessageDigits(r, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, b := range h {
+		if digits[i] != int(b) {
+			t.Fatalf("digit %d: expected %d, got %d", i, b, digits[i])
+		}
+	}
+	// Signatures don't verify with the other construction.
+	if otssha256.Verify(pub, msg, sig) {
+		t.Fatalf("prefix signature verified with SP-800-106")
+	}
+	sig, err = otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Verify(pub, msg, sig) {
+		t.Fatalf("SP-800-106 signature verified with prefix construction")
+	}
+}
+
+func TestWithChainStart(t *testing.T) {
+	var calls int
+	tweak := func(block []byte, index int) []byte {
+		calls++
+		h := sha256.New()
+		h.Write([]byte{byte(index >> 8), byte(index)})
+		h.Write(block)
+		return h.Sum(nil)
+	}
+	s := NewScheme(sha256.New, rand.Reader, WithChainStart(tweak))
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != s.NumChains() {
+		t.Fatalf("expected %d transform calls, got %d", s.NumChains(), calls)
+	}
+	plainPub, err := otssha256.PublicKeyFromPrivate(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(pub, plainPub) {
+		t.Fatalf("transform doesn't change public key")
+	}
+	msg := []byte(testMessage)
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Verify(pub, msg, sig) {
+		t.Fatalf("signature verification failed")
+	}
+	if otssha256.Verify(plainPub, msg, sig) {
+		t.Fatalf("verified signature with transform by scheme without it")
+	}
+	if s.ID() != "" {
+		t.Fatalf("expected empty ID, got %q", s.ID())
+	}
+	if _, err := s.MarshalConfig(); err == nil {
+		t.Fatalf("no error marshaling scheme with transform")
+	}
+}
+
+func TestWithRand(t *testing.T) {
+	orig := NewScheme2(sha256.New, sha512.New, rand.Reader, WithW(4))
+	seed := bytes.Repeat([]byte{1, 2, 3}, orig.PrivateKeySize())
+	s1 := orig.WithRand(bytes.NewReader(seed))
+	s2 := orig.WithRand(bytes.NewReader(seed))
+	if s1.Params() != orig.Params() {
+		t.Fatalf("expected %+v, got %+v", orig.Params(), s1.Params())
+	}
+	priv1, pub1, err := s1.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv2, pub2, err := s2.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priv1, priv2) || !bytes.Equal(pub1, pub2) {
+		t.Fatalf("schemes with the same reader generated different keys")
+	}
+	if !bytes.Equal(priv1, seed[:orig.PrivateKeySize()]) {
+		t.Fatalf("private key is not read from the scheme's reader")
+	}
+	if orig.rand != rand.Reader {
+		t.Fatalf("original scheme reader changed")
+	}
+}
+
+func TestRecoverPublicKeyInto(t *testing.T) {
+	priv, pub, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := make([]byte, otssha256.PublicKeySize())
+	if err := otssha256.RecoverPublicKeyInto(msg, sig, dst); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dst, pub) {
+		t.Fatalf("expected %x, got %x", pub, dst)
+	}
+	if err := otssha256.RecoverPublicKeyInto(msg, sig, dst[1:]); err == nil {
+		t.Fatalf("no error for wrong destination size")
+	}
+	if err := otssha256.RecoverPublicKeyInto(msg, sig[1:], dst); err == nil {
+		t.Fatalf("no error for wrong signature size")
+	}
+}
+
+func TestSignatureLayout(t *testing.T) {
+	for _, s := range []*Scheme{
+		otssha256Insecure,
+		NewScheme2(sha256.New, sha512.New, zeroReader, WithW(4)),
+		NewScheme(sha256.New, zeroReader, WithoutRandomizedHashing()),
+	} {
+		priv, _, err := s.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := []byte(testMessage)
+		sig, err := s.Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.ChainOffset(s.NumChains()) != s.SignatureSize() {
+			t.Fatalf("last chain offset doesn't match signature size")
+		}
+		// Reconstruct signature from randomization parameter and chains.
+		r := sig[s.RandomizerOffset() : s.RandomizerOffset()+s.RandomizerLen()]
+		digits, err := s.MessageDigits(r, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(digits) != s.NumChains() {
+			t.Fatalf("expected %d digits, got %d", s.NumChains(), len(digits))
+		}
+		rebuilt := append([]byte(nil), r...)
+		blockHash := s.chainFunc()
+		blockSize := s.ChainOffset(1) - s.ChainOffset(0)
+		for i, v := range digits {
+			if s.ChainOffset(i) != len(rebuilt) {
+				t.Fatalf("chain %d: wrong offset %d", i, s.ChainOffset(i))
+			}
+			rebuilt = append(rebuilt, hashBlock(blockHash, priv[i*blockSize:(i+1)*blockSize], v)...)
+		}
+		if !bytes.Equal(rebuilt, sig) {
+			t.Fatalf("reconstructed signature differs")
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("no panic for chain index out of range")
+		}
+	}()
+	otssha256.ChainOffset(otssha256.NumChains() + 1)
+}
+
+func TestW(t *testing.T) {
+	tests := []struct {
+		w      int
+		chains int
+	}{
+		{1, 256 + 10},
+		{2, 128 + 5},
+		{4, 64 + 3},
+		{8, 32 + 2},
+		{16, 16 + 2},
+	}
+	for _, test := range tests {
+		if test.w == 16 && testing.Short() {
+			continue
+		}
+		s := NewScheme(sha256.New, rand.Reader, WithW(test.w))
+		if s.PrivateKeySize() != test.chains*32 {
+			t.Errorf("w=%d: private key size: expected %d, got %d", test.w, test.chains*32, s.PrivateKeySize())
+		}
+		if s.SignatureSize() != 32+test.chains*32 {
+			t.Errorf("w=%d: signature size: expected %d, got %d", test.w, 32+test.chains*32, s.SignatureSize())
+		}
+		priv, pub, err := s.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := []byte(testMessage)
+		sig, err := s.Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.Verify(pub, msg, sig) {
+			t.Errorf("w=%d: failed to verify correct signature", test.w)
+		}
+		if s.Verify(pub, msg[1:], sig) {
+			t.Errorf("w=%d: verified wrong message", test.w)
+		}
+		digits, err := s.MessageDigits(sig[:32], msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(digits) != test.chains {
+			t.Errorf("w=%d: expected %d digits, got %d", test.w, test.chains, len(digits))
+		}
+		if !s.VerifyDigits(pub, digits, sig) {
+			t.Errorf("w=%d: failed to verify digits", test.w)
+		}
+		var text Signature
+		b, _ := Signature(sig).MarshalText()
+		if err := text.UnmarshalText(b); err != nil {
+			t.Errorf("w=%d: unmarshaling signature: %s", test.w, err)
+		}
+	}
+}
+
+func TestRandomizerOptionsConflict(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithoutRandomizedHashing(), WithRandomizerLen(16)},
+		{WithRandomizerLen(16), WithoutRandomizedHashing()},
+	} {
+		if _, err := NewSchemeChecked(sha256.New, sha256.New, rand.Reader, opts...); err == nil {
+			t.Fatalf("no error for conflicting randomizer options")
+		}
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("no panic for conflicting randomizer options")
+				}
+			}()
+			NewScheme(sha256.New, rand.Reader, opts...)
+		}()
+	}
+	if _, err := SchemeByID("wots-sha256-norand-r16", rand.Reader); err == nil {
+		t.Fatalf("no error for conflicting randomizer options in scheme ID")
+	}
+}
+
+func TestWOddHashSize(t *testing.T) {
+	// The scheme must be refused up front, before Verify, which doesn't
+	// validate parameters, can index past the end of the digest.
+	func() {
+		defer func() {
+			if r := recover(); r != "wots: message hash output size must be even for w=16" {
+				t.Fatalf("expected panic for odd hash size with w=16, got %v", r)
+			}
+		}()
+		s := NewScheme(newXOFHash(17), rand.Reader, WithW(16))
+		s.Verify(make([]byte, s.PublicKeySize()), []byte(testMessage), make([]byte, s.SignatureSize()))
+	}()
+	RegisterHash("test_xof17", newXOFHash(17))
+	defer unregisterHash("test_xof17")
+	if _, err := SchemeByID("wots-test_xof17-w16", rand.Reader); err == nil {
+		t.Fatalf("expected error for odd hash size with w=16")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic for unsupported w")
+		}
+	}()
+	WithW(3)
+}
+
+func TestMaxHashSize(t *testing.T) {
+	s := NewScheme(newXOFHash(MaxHashSize), rand.Reader)
+	// 128 message digits and 2 checksum digits.
+	if s.numChains != 130 {
+		t.Fatalf("expected 130 chains, got %d", s.numChains)
+	}
+	if s.PrivateKeySize() != 130*128 {
+		t.Fatalf("private key size: expected %d, got %d", 130*128, s.PrivateKeySize())
+	}
+	if s.SignatureSize() != 128+130*128 {
+		t.Fatalf("signature size: expected %d, got %d", 128+130*128, s.SignatureSize())
+	}
+	// Maximum checksum is 128*256 = 32768 for all-zero digits.
+	checksum := s.appendChecksum(nil, make([]int, 128))
+	if len(checksum) != 2 || checksum[0] != 128 || checksum[1] != 0 {
+		t.Fatalf("wrong maximum checksum digits: %v", checksum)
+	}
+	digits := make([]int, 128)
+	for i := range digits {
+		digits[i] = 255
+	}
+	checksum = s.appendChecksum(nil, digits)
+	if len(checksum) != 2 || checksum[0] != 0 || checksum[1] != 128 {
+		t.Fatalf("wrong minimum checksum digits: %v", checksum)
+	}
+
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(priv) != s.PrivateKeySize() || len(pub) != s.PublicKeySize() {
+		t.Fatalf("wrong key sizes: %d, %d", len(priv), len(pub))
+	}
+	msg := []byte(testMessage)
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != s.SignatureSize() {
+		t.Fatalf("wrong signature size: %d", len(sig))
+	}
+	if !s.Verify(pub, msg, sig) {
+		t.Fatalf("failed to verify correct signature")
+	}
+	sig[len(sig)-1] ^= 1
+	if s.Verify(pub, msg, sig) {
+		t.Fatalf("verified wrong signature")
+	}
+}
+
 type devZero int
 
 func (z *devZero) Read(b []byte) (int, error) {
@@ -110,14 +1145,305 @@ var zeroReader = new(devZero)
 var otssha256Insecure = NewScheme(sha256.New, zeroReader)
 
 func BenchmarkSignVerifySHA256(b *testing.B) {
+	s := NewScheme(sha256.New, NewDeterministicReader([]byte("benchmark")))
+	msg := []byte(testMessage)
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sig, _ := s.Sign(priv, msg)
+		s.Verify(pub, msg, sig)
+	}
+}
+
+func TestWithoutRandomizedHashing(t *testing.T) {
+	s := NewScheme(sha256.New, rand.Reader, WithoutRandomizedHashing())
+	if s.SignatureSize() != otssha256.SignatureSize()-sha256.Size {
+		t.Fatalf("expected signature size %d, got %d", otssha256.SignatureSize()-sha256.Size, s.SignatureSize())
+	}
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != s.SignatureSize() {
+		t.Fatalf("expected signature length %d, got %d", s.SignatureSize(), len(sig))
+	}
+	if !s.Verify(pub, msg, sig) {
+		t.Fatalf("signature verification failed")
+	}
+	if !s.VerifyConstantTime(pub, msg, sig) {
+		t.Fatalf("constant-time signature verification failed")
+	}
+	if s.Verify(pub, []byte("wrong message"), sig) {
+		t.Fatalf("verified signature for wrong message")
+	}
+	// Signing is deterministic.
+	sig2, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, sig2) {
+		t.Fatalf("signatures differ")
+	}
+	// Digits come from the plain message hash.
+	h := sha256.Sum256(msg)
+	digits, err := s.MessageDigits(nil, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, b := range h {
+		if digits[i] != int(b) {
+			t.Fatalf("digit %d: expected %d, got %d", i, b, digits[i])
+		}
+	}
+	if !isSignatureSize(len(sig)) {
+		t.Fatalf("signature size %d not recognized", len(sig))
+	}
+}
+
+func benchmarkVerify(b *testing.B, verify func(PublicKey, []byte, []byte) bool) {
 	msg := []byte(testMessage)
 	priv, pub, err := otssha256Insecure.GenerateKeyPair()
 	if err != nil {
 		b.Fatal(err)
 	}
+	sig, err := otssha256Insecure.Sign(priv, msg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		verify(pub, msg, sig)
+	}
+}
+
+func BenchmarkVerifySHA256(b *testing.B) {
+	benchmarkVerify(b, otssha256Insecure.Verify)
+}
+
+func BenchmarkVerifySHA512(b *testing.B) {
+	s := NewScheme(sha512.New, zeroReader)
+	msg := []byte(testMessage)
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		b.Fatal(err)
+	}
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(sig)))
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		sig, _ := otssha256Insecure.Sign(priv, msg)
-		otssha256Insecure.Verify(pub, msg, sig)
+		s.Verify(pub, msg, sig)
+	}
+}
+
+func BenchmarkVerifyConstantTimeSHA256(b *testing.B) {
+	benchmarkVerify(b, otssha256Insecure.VerifyConstantTime)
+}
+
+func benchmarkRecoverPublicKey(b *testing.B, recover func(msg, sig []byte) error) {
+	priv, _, err := otssha256.GenerateKeyPair()
+	if err != nil {
+		b.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := otssha256.Sign(priv, msg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := recover(msg, sig); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRecoverPublicKey(b *testing.B) {
+	benchmarkRecoverPublicKey(b, func(msg, sig []byte) error {
+		_, err := otssha256.RecoverPublicKey(msg, sig)
+		return err
+	})
+}
+
+func BenchmarkRecoverPublicKeyInto(b *testing.B) {
+	dst := make([]byte, otssha256.PublicKeySize())
+	benchmarkRecoverPublicKey(b, func(msg, sig []byte) error {
+		return otssha256.RecoverPublicKeyInto(msg, sig, dst)
+	})
+}
+
+func benchmarkSignVerifyW(b *testing.B, w int) {
+	s := NewScheme(sha256.New, zeroReader, WithW(w))
+	msg := []byte(testMessage)
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sig, _ := s.Sign(priv, msg)
+		s.Verify(pub, msg, sig)
+	}
+}
+
+func BenchmarkSignVerifyW4(b *testing.B)  { benchmarkSignVerifyW(b, 4) }
+func BenchmarkSignVerifyW8(b *testing.B)  { benchmarkSignVerifyW(b, 8) }
+func BenchmarkSignVerifyW16(b *testing.B) { benchmarkSignVerifyW(b, 16) }
+
+func BenchmarkSignZeroDigits(b *testing.B) {
+	// With w=1 digits are message digest bits, so every zero bit makes the
+	// signing path copy a private key block without hashing. Find a message
+	// with many zero digits for the fixed all-zero randomization parameter.
+	s := NewScheme(sha256.New, zeroReader, WithW(1))
+	r := make([]byte, 32)
+	var msg []byte
+	best := -1
+	for i := 0; i < 1000; i++ {
+		m := []byte(strconv.Itoa(i))
+		zeros := 0
+		for _, v := range s.messageDigits(r, m)[:s.numDigits] {
+			if v == 0 {
+				zeros++
+			}
+		}
+		if zeros > best {
+			msg, best = m, zeros
+		}
+	}
+	priv, _, err := s.GenerateKeyPair()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Sign(priv, msg)
+	}
+}
+
+func BenchmarkVerifyTimingSpread(b *testing.B) {
+	// Verification hashes each chain 2^w - digit times, so its time depends
+	// on the message. Find messages with the lowest and the highest sums of
+	// message digest digits for the fixed all-zero randomization parameter,
+	// which are the slowest and the fastest to verify, and compare Verify
+	// with VerifyConstantTime for them.
+	s := otssha256Insecure
+	r := make([]byte, s.RandomizerLen())
+	var low, high []byte
+	lowSum, highSum := -1, -1
+	for i := 0; i < 1000; i++ {
+		m := []byte(strconv.Itoa(i))
+		sum := 0
+		for _, v := range s.messageDigits(r, m)[:s.numDigits] {
+			sum += v
+		}
+		if lowSum < 0 || sum < lowSum {
+			low, lowSum = m, sum
+		}
+		if sum > highSum {
+			high, highSum = m, sum
+		}
+	}
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, v := range []struct {
+		name   string
+		verify func(PublicKey, []byte, []byte) bool
+	}{
+		{"Verify", s.Verify},
+		{"VerifyConstantTime", s.VerifyConstantTime},
+	} {
+		for _, m := range []struct {
+			name string
+			msg  []byte
+		}{
+			{"LowDigits", low},
+			{"HighDigits", high},
+		} {
+			sig, err := s.Sign(priv, m.msg)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.Run(v.name+"/"+m.name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					v.verify(pub, m.msg, sig)
+				}
+			})
+		}
+	}
+}
+
+func TestHashInstancesPerOperation(t *testing.T) {
+	var chainCalls, hashCalls int
+	chain := func() hash.Hash { chainCalls++; return sha256.New() }
+	h := func() hash.Hash { hashCalls++; return sha256.New() }
+	s := NewScheme2(h, chain, zeroReader)
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := []struct {
+		name string
+		f    func()
+	}{
+		{"PublicKeyFromPrivate", func() { s.PublicKeyFromPrivate(priv) }},
+		{"Sign", func() { s.Sign(priv, msg) }},
+		{"Verify", func() { s.Verify(pub, msg, sig) }},
+		{"RecoverPublicKey", func() { s.RecoverPublicKey(msg, sig) }},
+	}
+	for _, op := range ops {
+		chainCalls, hashCalls = 0, 0
+		op.f()
+		if chainCalls != 1 || hashCalls != 1 {
+			t.Errorf("%s: expected 1 chain and 1 hash instance, got %d and %d",
+				op.name, chainCalls, hashCalls)
+		}
+	}
+}
+
+func TestAllocsPerOperation(t *testing.T) {
+	s := otssha256Insecure
+	priv, pub, err := s.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(testMessage)
+	sig, err := s.Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := []struct {
+		name string
+		max  float64
+		f    func()
+	}{
+		{"PublicKeyFromPrivate", 4, func() { s.PublicKeyFromPrivate(priv) }},
+		{"Sign", 8, func() { s.Sign(priv, msg) }},
+		{"Verify", 7, func() { s.Verify(pub, msg, sig) }},
+		{"RecoverPublicKey", 7, func() { s.RecoverPublicKey(msg, sig) }},
+	}
+	for _, op := range ops {
+		if n := testing.AllocsPerRun(10, op.f); n > op.max {
+			t.Errorf("%s: expected at most %v allocations, got %v", op.name, op.max, n)
+		}
 	}
 }